@@ -0,0 +1,128 @@
+package knowledge
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunker 按段落切分文本，段落过长时再按句子切分，相邻分块保留少量重叠
+type Chunker struct {
+	Size    int
+	Overlap int
+}
+
+func NewChunker(size, overlap int) *Chunker {
+	if size <= 0 {
+		size = 400
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+	return &Chunker{Size: size, Overlap: overlap}
+}
+
+// Split 切分文本，markdown 标题会作为前缀保留在其下的每个分块中
+func (c *Chunker) Split(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var chunks []string
+	var current strings.Builder
+	var heading string
+	hasContent := false
+
+	// start 开始一个新分块：先写标题，再带入上一块的结尾，避免句子在边界处失去上下文
+	start := func(previous string) {
+		current.Reset()
+		if heading != "" {
+			current.WriteString(heading)
+			current.WriteString("\n")
+		}
+		if tail := tailRunes(previous, c.Overlap); tail != "" && tail != previous {
+			current.WriteString(tail)
+			current.WriteString("\n")
+		}
+	}
+	flush := func() {
+		content := strings.TrimSpace(current.String())
+		if hasContent && content != "" {
+			chunks = append(chunks, content)
+		}
+		hasContent = false
+		start(content)
+	}
+
+	for _, paragraph := range splitParagraphs(text) {
+		if strings.HasPrefix(paragraph, "#") {
+			flush()
+			heading = strings.TrimSpace(strings.TrimLeft(paragraph, "#"))
+			start("")
+			continue
+		}
+
+		for _, piece := range c.splitLong(paragraph) {
+			if hasContent && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(piece) > c.Size {
+				flush()
+			}
+			current.WriteString(piece)
+			current.WriteString("\n")
+			hasContent = true
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitLong 把超过分块大小的段落按句末标点切开，单句仍过长时按字符硬切
+func (c *Chunker) splitLong(paragraph string) []string {
+	if utf8.RuneCountInString(paragraph) <= c.Size {
+		return []string{paragraph}
+	}
+
+	var pieces []string
+	var sentence []rune
+	for _, r := range paragraph {
+		sentence = append(sentence, r)
+		if strings.ContainsRune("。！？!?；;.", r) || len(sentence) >= c.Size {
+			pieces = append(pieces, string(sentence))
+			sentence = sentence[:0]
+		}
+	}
+	if len(sentence) > 0 {
+		pieces = append(pieces, string(sentence))
+	}
+	return pieces
+}
+
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	var current []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, strings.Join(current, " "))
+				current = nil
+			}
+			if line != "" {
+				paragraphs = append(paragraphs, line)
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+	return paragraphs
+}
+
+func tailRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[len(runes)-n:])
+}
@@ -0,0 +1,25 @@
+package knowledge
+
+import "time"
+
+// Config 知识库配置，角色服务和对话服务使用同一份嵌入配置，保证向量可比
+type Config struct {
+	Enable       bool           `json:",default=true"`
+	Embedder     EmbedderConfig `json:",optional"`
+	ChunkSize    int            `json:",default=400"`     // 每个分块的最大字符数
+	ChunkOverlap int            `json:",default=60"`      // 相邻分块重叠的字符数
+	MaxFileSize  int64          `json:",default=1048576"` // 上传文件大小上限（字节）
+	TopK         int            `json:",default=4"`       // 每轮对话检索的分块数
+	MinScore     float64        `json:",default=0.15"`    // 相似度低于该值的分块不引用
+	CacheTTL     time.Duration  `json:",default=5m"`      // 进程内向量索引的缓存时间
+}
+
+// 嵌入模型配置
+type EmbedderConfig struct {
+	Type      string `json:",default=hash"` // hash, openai
+	Dimension int    `json:",default=512"`  // 仅 hash 类型使用
+	BaseURL   string `json:",optional"`
+	Model     string `json:",optional"`
+	APIKey    string `json:",optional"`
+	APIKeyEnv string `json:",optional"` // 从环境变量读取密钥，优先于 APIKey
+}
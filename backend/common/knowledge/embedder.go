@@ -0,0 +1,185 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	EmbedderTypeHash   = "hash"
+	EmbedderTypeOpenAI = "openai"
+)
+
+// Embedder 把文本转换为向量，Name 会随分块一起保存，检索时只比较同一嵌入模型产生的向量
+type Embedder interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder 按配置创建嵌入模型，默认使用无需外部服务的哈希嵌入
+func NewEmbedder(c EmbedderConfig) (Embedder, error) {
+	switch c.Type {
+	case "", EmbedderTypeHash:
+		return NewHashEmbedder(c.Dimension), nil
+	case EmbedderTypeOpenAI:
+		return newOpenAIEmbedder(c)
+	default:
+		return nil, fmt.Errorf("unsupported embedder type %q", c.Type)
+	}
+}
+
+// HashEmbedder 对词和相邻字符二元组做特征哈希，得到归一化的词袋向量
+type HashEmbedder struct {
+	Dimension int
+}
+
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	if dimension <= 0 {
+		dimension = 512
+	}
+	return &HashEmbedder{Dimension: dimension}
+}
+
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("%s-%d", EmbedderTypeHash, e.Dimension)
+}
+
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, e.embed(text))
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.Dimension)
+	for _, feature := range features(text) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		// 用哈希的最高位决定符号，减少冲突带来的偏差
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vector[sum%uint64(e.Dimension)] += sign
+	}
+	normalize(vector)
+	return vector
+}
+
+// features 英文按单词，中文等无空格文字按相邻二元组提取特征
+func features(text string) []string {
+	var result []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			result = append(result, "w:"+string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			result = append(result, "c:"+string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			result = append(result, "c:"+string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return result
+}
+
+// openAIEmbedder 调用 OpenAI 兼容的 embeddings 接口
+type openAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+func newOpenAIEmbedder(c EmbedderConfig) (*openAIEmbedder, error) {
+	if c.Model == "" {
+		return nil, fmt.Errorf("embedder model is required")
+	}
+	apiKey := c.APIKey
+	if c.APIKeyEnv != "" {
+		if value := os.Getenv(c.APIKeyEnv); value != "" {
+			apiKey = value
+		}
+	}
+
+	config := openai.DefaultConfig(apiKey)
+	if c.BaseURL != "" {
+		config.BaseURL = c.BaseURL
+	}
+	return &openAIEmbedder{
+		client: openai.NewClientWithConfig(config),
+		model:  c.Model,
+	}, nil
+}
+
+func (e *openAIEmbedder) Name() string {
+	return fmt.Sprintf("%s-%s", EmbedderTypeOpenAI, e.model)
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: texts,
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("unexpected embedding index %d", item.Index)
+		}
+		vector := item.Embedding
+		normalize(vector)
+		vectors[item.Index] = vector
+	}
+	return vectors, nil
+}
+
+func normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
@@ -0,0 +1,62 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkerKeepsHeadingAndSize(t *testing.T) {
+	text := "# 生平\n" + strings.Repeat("爱因斯坦出生于德国乌尔姆。", 20) + "\n\n# 相对论\n1905年他发表了狭义相对论。"
+	chunks := NewChunker(60, 10).Split(text)
+	if len(chunks) < 3 {
+		t.Fatalf("expected at least 3 chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		// 标题和重叠部分会略微超过分块大小
+		if utf8.RuneCountInString(chunk) > 60+10+len("相对论")+2 {
+			t.Errorf("chunk too long: %d runes", utf8.RuneCountInString(chunk))
+		}
+	}
+	if !strings.HasPrefix(chunks[1], "生平") {
+		t.Errorf("expected heading prefix, got %q", chunks[1])
+	}
+	last := chunks[len(chunks)-1]
+	if !strings.HasPrefix(last, "相对论") || strings.Contains(last, "乌尔姆") {
+		t.Errorf("heading section should start a new chunk, got %q", last)
+	}
+}
+
+func TestHashEmbedderRanksRelevantChunk(t *testing.T) {
+	embedder := NewHashEmbedder(256)
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"1905年爱因斯坦发表了狭义相对论",
+		"莎士比亚写了哈姆雷特",
+		"相对论是什么时候发表的",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	relevant := Cosine(vectors[2], vectors[0])
+	irrelevant := Cosine(vectors[2], vectors[1])
+	if relevant <= irrelevant {
+		t.Errorf("expected relevant score %.3f > irrelevant score %.3f", relevant, irrelevant)
+	}
+}
+
+func TestVectorRoundTrip(t *testing.T) {
+	vector := []float32{0.5, -0.25, 1, 0}
+	decoded, err := DecodeVector(EncodeVector(vector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range vector {
+		if decoded[i] != vector[i] {
+			t.Fatalf("index %d: got %v, want %v", i, decoded[i], vector[i])
+		}
+	}
+	if _, err := DecodeVector([]byte{1, 2, 3}); err == nil {
+		t.Error("expected error for truncated vector")
+	}
+}
@@ -0,0 +1,248 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// Hit 一条检索结果
+type Hit struct {
+	ChunkID       int64   `json:"chunk_id"`
+	DocumentID    int64   `json:"document_id"`
+	DocumentTitle string  `json:"document_title"`
+	ChunkIndex    int32   `json:"chunk_index"`
+	Content       string  `json:"content"`
+	Score         float64 `json:"score"`
+}
+
+type indexedChunk struct {
+	hit    Hit
+	vector []float32
+}
+
+type characterIndex struct {
+	chunks   []indexedChunk
+	version  int64
+	loadedAt time.Time
+}
+
+// Versions 记录每个角色知识库的版本号，文档变更时递增，各进程据此丢弃过期的缓存索引
+type Versions interface {
+	Get(ctx context.Context, characterID int64) (int64, error)
+	Incr(ctx context.Context, characterID int64) error
+}
+
+const versionKey = "knowledge:version:%d"
+
+// redisVersions 版本号保存在 Redis，角色服务和对话服务共用
+type redisVersions struct {
+	rdb *redis.Client
+}
+
+func NewRedisVersions(rdb *redis.Client) Versions {
+	return &redisVersions{rdb: rdb}
+}
+
+func (v *redisVersions) Get(ctx context.Context, characterID int64) (int64, error) {
+	version, err := v.rdb.Get(ctx, fmt.Sprintf(versionKey, characterID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (v *redisVersions) Incr(ctx context.Context, characterID int64) error {
+	return v.rdb.Incr(ctx, fmt.Sprintf(versionKey, characterID)).Err()
+}
+
+// Retriever 以 MySQL 为准，在进程内按角色缓存向量索引，检索时做暴力余弦相似度计算。
+// 缓存在超过 ttl 或版本号变化时重新加载，versions 为 nil 时只按 ttl 过期
+type Retriever struct {
+	db       *gorm.DB
+	embedder Embedder
+	versions Versions
+	ttl      time.Duration
+
+	// fetch 从数据库读取角色的全部分块，测试时可替换
+	fetch func(ctx context.Context, characterID int64) ([]indexedChunk, error)
+
+	mu      sync.RWMutex
+	indexes map[int64]*characterIndex
+}
+
+func NewRetriever(db *gorm.DB, embedder Embedder, versions Versions, ttl time.Duration) *Retriever {
+	r := &Retriever{
+		db:       db,
+		embedder: embedder,
+		versions: versions,
+		ttl:      ttl,
+		indexes:  make(map[int64]*characterIndex),
+	}
+	r.fetch = r.fetchChunks
+	return r
+}
+
+// Search 返回与问题最相似的 topK 个分块，低于 minScore 的结果会被过滤
+func (r *Retriever) Search(ctx context.Context, characterID int64, query string, topK int, minScore float64) ([]Hit, error) {
+	index, err := r.load(ctx, characterID)
+	if err != nil || len(index.chunks) == 0 {
+		return nil, err
+	}
+
+	vectors, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	queryVector := vectors[0]
+
+	hits := make([]Hit, 0, len(index.chunks))
+	for _, chunk := range index.chunks {
+		score := Cosine(queryVector, chunk.vector)
+		if score < minScore {
+			continue
+		}
+		hit := chunk.hit
+		hit.Score = score
+		hits = append(hits, hit)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if topK > 0 && len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+// Invalidate 文档变更后丢弃角色的缓存索引，并递增版本号通知其他进程
+func (r *Retriever) Invalidate(ctx context.Context, characterID int64) error {
+	r.mu.Lock()
+	delete(r.indexes, characterID)
+	r.mu.Unlock()
+
+	if r.versions == nil {
+		return nil
+	}
+	return r.versions.Incr(ctx, characterID)
+}
+
+func (r *Retriever) load(ctx context.Context, characterID int64) (*characterIndex, error) {
+	// 先读版本号再加载，加载期间发生的变更会在下次检索时发现
+	var version int64
+	versionKnown := r.versions != nil
+	if versionKnown {
+		v, err := r.versions.Get(ctx, characterID)
+		if err != nil {
+			// 版本号不可用时退化为只按缓存时间过期
+			logx.WithContext(ctx).Errorf("knowledge: get version of character %d failed: %v", characterID, err)
+			versionKnown = false
+		}
+		version = v
+	}
+
+	r.mu.RLock()
+	index, ok := r.indexes[characterID]
+	r.mu.RUnlock()
+	if ok && time.Since(index.loadedAt) < r.ttl && (!versionKnown || index.version == version) {
+		return index, nil
+	}
+
+	chunks, err := r.fetch(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	index = &characterIndex{
+		chunks:   chunks,
+		version:  version,
+		loadedAt: time.Now(),
+	}
+
+	r.mu.Lock()
+	r.indexes[characterID] = index
+	r.mu.Unlock()
+	return index, nil
+}
+
+func (r *Retriever) fetchChunks(ctx context.Context, characterID int64) ([]indexedChunk, error) {
+	// 只读取检索需要的列，表结构由角色服务维护
+	var rows []struct {
+		ID            int64  `gorm:"column:id"`
+		DocumentID    int64  `gorm:"column:document_id"`
+		DocumentTitle string `gorm:"column:document_title"`
+		ChunkIndex    int32  `gorm:"column:chunk_index"`
+		Content       string `gorm:"column:content"`
+		Embedding     []byte `gorm:"column:embedding"`
+	}
+	err := r.db.WithContext(ctx).
+		Table("character_knowledge_chunks AS c").
+		Select("c.id, c.document_id, d.title AS document_title, c.chunk_index, c.content, c.embedding").
+		Joins("JOIN character_knowledge_documents d ON d.id = c.document_id").
+		Where("c.character_id = ? AND c.embedder = ?", characterID, r.embedder.Name()).
+		Order("c.document_id ASC, c.chunk_index ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]indexedChunk, 0, len(rows))
+	for _, row := range rows {
+		vector, err := DecodeVector(row.Embedding)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, indexedChunk{
+			hit: Hit{
+				ChunkID:       row.ID,
+				DocumentID:    row.DocumentID,
+				DocumentTitle: row.DocumentTitle,
+				ChunkIndex:    row.ChunkIndex,
+				Content:       row.Content,
+			},
+			vector: vector,
+		})
+	}
+	return chunks, nil
+}
+
+// Citation 保存在消息元数据中的引用信息
+type Citation struct {
+	Index         int     `json:"index"`
+	ChunkID       int64   `json:"chunk_id"`
+	DocumentID    int64   `json:"document_id"`
+	DocumentTitle string  `json:"document_title"`
+	ChunkIndex    int32   `json:"chunk_index"`
+	Score         float64 `json:"score"`
+	Snippet       string  `json:"snippet"`
+}
+
+// citationSnippetLength 引用片段保留的字符数
+const citationSnippetLength = 120
+
+// Citations 把检索结果转换为从 1 开始编号的引用，编号与提示词中的资料序号一致
+func Citations(hits []Hit) []Citation {
+	citations := make([]Citation, 0, len(hits))
+	for i, hit := range hits {
+		snippet := []rune(hit.Content)
+		if len(snippet) > citationSnippetLength {
+			snippet = append(snippet[:citationSnippetLength], '…')
+		}
+		citations = append(citations, Citation{
+			Index:         i + 1,
+			ChunkID:       hit.ChunkID,
+			DocumentID:    hit.DocumentID,
+			DocumentTitle: hit.DocumentTitle,
+			ChunkIndex:    hit.ChunkIndex,
+			Score:         math.Round(hit.Score*1000) / 1000,
+			Snippet:       string(snippet),
+		})
+	}
+	return citations
+}
@@ -0,0 +1,87 @@
+package knowledge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryVersions 进程内的版本号，模拟多个服务共用的 Redis
+type memoryVersions struct {
+	mu       sync.Mutex
+	versions map[int64]int64
+}
+
+func (v *memoryVersions) Get(_ context.Context, characterID int64) (int64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.versions[characterID], nil
+}
+
+func (v *memoryVersions) Incr(_ context.Context, characterID int64) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.versions[characterID]++
+	return nil
+}
+
+// newTestRetriever 分块来自 docs 而不是数据库
+func newTestRetriever(versions Versions, docs *[]string) *Retriever {
+	embedder := NewHashEmbedder(256)
+	r := NewRetriever(nil, embedder, versions, time.Hour)
+	r.fetch = func(ctx context.Context, characterID int64) ([]indexedChunk, error) {
+		vectors, err := embedder.Embed(ctx, *docs)
+		if err != nil {
+			return nil, err
+		}
+		chunks := make([]indexedChunk, 0, len(*docs))
+		for i, doc := range *docs {
+			chunks = append(chunks, indexedChunk{hit: Hit{ChunkID: int64(i + 1), Content: doc}, vector: vectors[i]})
+		}
+		return chunks, nil
+	}
+	return r
+}
+
+func TestInvalidateEvictsOtherProcessCache(t *testing.T) {
+	ctx := context.Background()
+	versions := &memoryVersions{versions: map[int64]int64{}}
+	docs := []string{"1905年爱因斯坦发表了狭义相对论"}
+	chat := newTestRetriever(versions, &docs)      // 对话服务中的检索器
+	character := newTestRetriever(versions, &docs) // 角色服务中的检索器
+
+	hits, err := chat.Search(ctx, 1, "相对论是什么时候发表的", 4, 0.1)
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("initial search: %v %v", hits, err)
+	}
+
+	// 文档已删除，但缓存未过期前仍能检索到
+	docs = nil
+	if hits, _ := chat.Search(ctx, 1, "相对论是什么时候发表的", 4, 0.1); len(hits) != 1 {
+		t.Fatalf("expected cached hit before invalidation, got %v", hits)
+	}
+
+	if err := character.Invalidate(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := chat.Search(ctx, 1, "相对论是什么时候发表的", 4, 0.1); len(hits) != 0 {
+		t.Fatalf("deleted document still retrieved: %v", hits)
+	}
+}
+
+func TestInvalidateWithoutVersions(t *testing.T) {
+	ctx := context.Background()
+	docs := []string{"莎士比亚写了哈姆雷特"}
+	r := newTestRetriever(nil, &docs)
+	if hits, _ := r.Search(ctx, 1, "哈姆雷特的作者", 4, 0.1); len(hits) != 1 {
+		t.Fatalf("initial search: %v", hits)
+	}
+	docs = nil
+	if err := r.Invalidate(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := r.Search(ctx, 1, "哈姆雷特的作者", 4, 0.1); len(hits) != 0 {
+		t.Fatalf("deleted document still retrieved: %v", hits)
+	}
+}
@@ -0,0 +1,40 @@
+package knowledge
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// EncodeVector 把向量编码为小端 float32 字节序列，存入 MySQL 的 BLOB 字段
+func EncodeVector(vector []float32) []byte {
+	buf := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// DecodeVector 解码 EncodeVector 生成的字节序列
+func DecodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector, nil
+}
+
+// Cosine 计算两个已归一化向量的余弦相似度
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
@@ -19,15 +19,42 @@ const (
 	AI_Role_Unknown   = "unknown"
 )
 
+// Message Type 消息表中保存的消息类型，AI回复统一保存为 ai，发送给模型时再转换为 assistant
+const (
+	Message_Type_User = "user"
+	Message_Type_AI   = "ai"
+)
+
 // AI SSE Event
 const (
-	AI_SSE_Event_Message    = "message"
-	AI_SSE_Event_Error      = "error"
-	AI_SSE_Event_Done       = "done"
-	AI_SSE_Event_End        = "end"
-	AI_SSE_Event_Stream     = "stream"
-	AI_SSE_Event_Progress   = "progress"
-	AI_SSE_Event_Unknown    = "unknown"
-	AI_SSE_Event_Thinking   = "thinking"
-	AI_SSE_Event_Stream_End = "stream_end"
+	AI_SSE_Event_Message      = "message"
+	AI_SSE_Event_Error        = "error"
+	AI_SSE_Event_Done         = "done"
+	AI_SSE_Event_End          = "end"
+	AI_SSE_Event_Stream       = "stream"
+	AI_SSE_Event_Progress     = "progress"
+	AI_SSE_Event_Unknown      = "unknown"
+	AI_SSE_Event_Thinking     = "thinking"
+	AI_SSE_Event_Stream_End   = "stream_end"
+	AI_SSE_Event_Pong         = "pong"
+	AI_SSE_Event_Title        = "title"
+	AI_SSE_Event_ToolCall     = "tool_call"
+	AI_SSE_Event_ToolResult   = "tool_result"
+	AI_SSE_Event_Conversation = "conversation" // 新建对话后的第一个事件，携带对话ID
 )
+
+// AI Tool 角色可使用的内置工具
+const (
+	AI_Tool_Roll_Dice        = "roll_dice"
+	AI_Tool_Current_Time     = "current_time"
+	AI_Tool_Convert_Unit     = "convert_unit"
+	AI_Tool_Search_Knowledge = "search_knowledge"
+)
+
+// AI_Tools 全部内置工具，角色配置工具时据此校验
+var AI_Tools = []string{
+	AI_Tool_Roll_Dice,
+	AI_Tool_Current_Time,
+	AI_Tool_Convert_Unit,
+	AI_Tool_Search_Knowledge,
+}
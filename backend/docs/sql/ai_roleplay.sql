@@ -68,6 +68,8 @@ CREATE TABLE `characters` (
   `prompt` text COMMENT '角色提示词',
   `personality` json DEFAULT NULL COMMENT '性格设置',
   `voice_settings` json DEFAULT NULL COMMENT '语音设置',
+  `tools` json DEFAULT NULL COMMENT '角色可使用的工具名称列表',
+  `providers` json DEFAULT NULL COMMENT '备用模型提供方名称列表，按顺序尝试',
   `status` tinyint(3) unsigned NOT NULL DEFAULT '1' COMMENT '状态：1正常 2禁用',
   `is_public` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否公开：1公开 0私有',
   `creator_id` bigint(20) unsigned DEFAULT NULL COMMENT '创建者ID，NULL表示系统预设',
@@ -116,6 +118,8 @@ CREATE TABLE `conversations` (
   `user_id` bigint(20) unsigned DEFAULT NULL COMMENT '用户ID，NULL表示匿名用户',
   `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
   `title` varchar(200) NOT NULL DEFAULT '新对话' COMMENT '对话标题',
+  `title_customized` tinyint(1) NOT NULL DEFAULT '0' COMMENT '标题是否由用户设置，设置后不再自动生成',
+  `turn_policy` varchar(20) NOT NULL DEFAULT '' COMMENT '群聊发言策略：round_robin轮流 mention点名 auto模型选择，为空表示单角色对话',
   `start_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '开始时间',
   `last_message_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最后消息时间',
   `message_count` int(11) NOT NULL DEFAULT '0' COMMENT '消息数量',
@@ -142,8 +146,13 @@ DROP TABLE IF EXISTS `messages`;
 CREATE TABLE `messages` (
   `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '消息ID',
   `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `parent_id` bigint(20) unsigned DEFAULT NULL COMMENT '上一条消息ID，为空表示对话的第一条',
+  `branch` int(11) NOT NULL DEFAULT '1' COMMENT '在同一父消息下的分支序号',
+  `is_active` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否为兄弟消息中当前选中的分支',
   `type` enum('user','ai') NOT NULL COMMENT '消息类型：user用户 ai系统',
+  `character_id` bigint(20) unsigned DEFAULT NULL COMMENT '发言角色ID，AI消息记录由哪个角色回复',
   `content` text NOT NULL COMMENT '消息内容',
+  `original_content` text DEFAULT NULL COMMENT '被遮蔽或拦截前的原文，仅管理员可见',
   `audio_id` bigint(20) unsigned DEFAULT NULL COMMENT '语音文件ID',
   `metadata` json DEFAULT NULL COMMENT '元数据，存储额外信息',
   `token_used` int(11) DEFAULT '0' COMMENT 'AI消息使用的token数',
@@ -151,6 +160,7 @@ CREATE TABLE `messages` (
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   PRIMARY KEY (`id`),
   KEY `idx_conversation_id` (`conversation_id`),
+  KEY `idx_parent_id` (`conversation_id`,`parent_id`),
   KEY `idx_type` (`type`),
   KEY `idx_created_at` (`created_at`),
   KEY `idx_audio_id` (`audio_id`),
@@ -206,6 +216,177 @@ CREATE TABLE `system_configs` (
   KEY `idx_is_public` (`is_public`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系统配置表';
 
+-- ====================================
+-- 9. 对话摘要表 (conversation_summaries)
+-- ====================================
+DROP TABLE IF EXISTS `conversation_summaries`;
+CREATE TABLE `conversation_summaries` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '摘要ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `summary` text NOT NULL COMMENT '摘要内容',
+  `covered_message_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '摘要覆盖到的最后一条消息ID',
+  `message_count` int(11) NOT NULL DEFAULT '0' COMMENT '摘要覆盖的消息数量',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_conversation_id` (`conversation_id`),
+  CONSTRAINT `fk_summaries_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对话摘要表';
+
+-- ====================================
+-- 10. 用户角色长期记忆表 (user_character_memories)
+-- ====================================
+DROP TABLE IF EXISTS `user_character_memories`;
+CREATE TABLE `user_character_memories` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '记忆ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `fact_key` varchar(50) NOT NULL DEFAULT '' COMMENT '事实主题，同一主题只保留最新一条',
+  `content` varchar(500) NOT NULL COMMENT '记忆内容',
+  `importance` tinyint(3) unsigned NOT NULL DEFAULT '3' COMMENT '重要程度：1-5',
+  `source_message_id` bigint(20) unsigned DEFAULT NULL COMMENT '提取来源消息ID',
+  `status` tinyint(3) unsigned NOT NULL DEFAULT '1' COMMENT '状态：1正常 2已删除',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_character` (`user_id`,`character_id`,`status`),
+  KEY `idx_fact_key` (`user_id`,`character_id`,`fact_key`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_memories_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_memories_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户角色长期记忆表';
+
+-- ====================================
+-- 11. 角色知识库文档表 (character_knowledge_documents)
+-- ====================================
+DROP TABLE IF EXISTS `character_knowledge_documents`;
+CREATE TABLE `character_knowledge_documents` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '文档ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `title` varchar(200) NOT NULL COMMENT '文档标题',
+  `file_name` varchar(255) NOT NULL COMMENT '原始文件名',
+  `format` varchar(20) NOT NULL DEFAULT 'text' COMMENT '格式：text/markdown',
+  `size` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '文件大小（字节）',
+  `chunk_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '分块数',
+  `embedder` varchar(100) NOT NULL COMMENT '嵌入模型',
+  `creator_id` bigint(20) unsigned NOT NULL COMMENT '上传者ID',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_knowledge_documents_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色知识库文档表';
+
+-- ====================================
+-- 12. 角色知识库分块表 (character_knowledge_chunks)
+-- ====================================
+DROP TABLE IF EXISTS `character_knowledge_chunks`;
+CREATE TABLE `character_knowledge_chunks` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '分块ID',
+  `document_id` bigint(20) unsigned NOT NULL COMMENT '文档ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `chunk_index` int(10) unsigned NOT NULL COMMENT '分块在文档中的序号',
+  `content` text NOT NULL COMMENT '分块内容',
+  `embedder` varchar(100) NOT NULL COMMENT '嵌入模型',
+  `embedding` mediumblob NOT NULL COMMENT '向量（小端float32序列）',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_character_embedder` (`character_id`,`embedder`),
+  KEY `idx_document_id` (`document_id`),
+  CONSTRAINT `fk_knowledge_chunks_document` FOREIGN KEY (`document_id`) REFERENCES `character_knowledge_documents` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色知识库分块表';
+
+-- ====================================
+-- 13. 群聊参与角色表 (conversation_participants)
+-- ====================================
+DROP TABLE IF EXISTS `conversation_participants`;
+CREATE TABLE `conversation_participants` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `position` int(11) NOT NULL DEFAULT '0' COMMENT '轮流发言的顺序，从0开始',
+  `status` tinyint(3) unsigned NOT NULL DEFAULT '1' COMMENT '状态：1正常 2已移出',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_conversation_character` (`conversation_id`,`character_id`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_participants_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_participants_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='群聊参与角色表';
+
+-- ====================================
+-- 14. 用户套餐表 (user_plans)
+-- ====================================
+DROP TABLE IF EXISTS `user_plans`;
+CREATE TABLE `user_plans` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `plan` varchar(20) NOT NULL DEFAULT 'free' COMMENT '套餐：free/pro，额度在对话服务配置中定义',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '到期时间，NULL表示长期有效，到期后按默认套餐计算',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`),
+  CONSTRAINT `fk_plans_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户套餐表';
+
+-- ====================================
+-- 15. 用户token用量表 (user_token_usage)
+-- ====================================
+DROP TABLE IF EXISTS `user_token_usage`;
+CREATE TABLE `user_token_usage` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `period_type` enum('day','month') NOT NULL COMMENT '周期类型：day按天 month按月',
+  `period` varchar(10) NOT NULL COMMENT '周期，如 2026-10-17、2026-10',
+  `tokens_used` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '已使用的token数',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_period` (`user_id`,`period_type`,`period`),
+  CONSTRAINT `fk_token_usage_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户token用量表';
+
+-- ====================================
+-- 16. 消息反馈表 (message_feedback)
+-- ====================================
+DROP TABLE IF EXISTS `message_feedback`;
+CREATE TABLE `message_feedback` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `message_id` bigint(20) unsigned NOT NULL COMMENT 'AI消息ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `character_id` bigint(20) unsigned DEFAULT NULL COMMENT '回复的角色ID',
+  `vote` tinyint(4) NOT NULL COMMENT '评价：1赞 -1踩',
+  `reasons` json DEFAULT NULL COMMENT '原因标签列表',
+  `comment` varchar(500) NOT NULL DEFAULT '' COMMENT '补充说明',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_message_user` (`message_id`,`user_id`),
+  KEY `idx_character_vote` (`character_id`,`vote`),
+  KEY `idx_created_at` (`created_at`),
+  CONSTRAINT `fk_feedback_message` FOREIGN KEY (`message_id`) REFERENCES `messages` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_feedback_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息反馈表';
+
+-- ====================================
+-- 17. 角色评分表 (character_ratings)
+-- ====================================
+DROP TABLE IF EXISTS `character_ratings`;
+CREATE TABLE `character_ratings` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `score` tinyint(3) unsigned NOT NULL COMMENT '评分：1-5星',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_character_user` (`character_id`,`user_id`),
+  CONSTRAINT `fk_ratings_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_ratings_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色评分表';
+
 -- ====================================
 -- 插入示例数据
 -- ====================================
@@ -241,7 +422,8 @@ INSERT INTO `system_configs` (`config_key`, `config_value`, `description`, `conf
 ('file_upload_max_size', '10485760', '文件上传最大大小(10MB)', 'number', 1),
 ('audio_max_duration', '300000', '音频最大时长(5分钟,毫秒)', 'number', 1),
 ('rate_limit_per_minute', '60', '每分钟请求限制', 'number', 0),
-('enable_anonymous_chat', 'true', '是否允许匿名聊天', 'boolean', 1);
+('enable_anonymous_chat', 'true', '是否允许匿名聊天', 'boolean', 1),
+('moderation_rules', '[{"type":"regex","pattern":"1[3-9]\\\\d{9}","action":"mask","reason":"手机号"}]', '内容审核规则：keyword/regex，处理方式 block/mask/flag', 'json', 0);
 
 -- 插入示例用户数据
 INSERT INTO `users` (`id`, `username`, `email`, `password_hash`, `nickname`, `avatar`, `bio`) VALUES
@@ -281,7 +463,8 @@ SET FOREIGN_KEY_CHECKS = 1;
 -- 为全文搜索创建索引
 ALTER TABLE `characters` ADD FULLTEXT KEY `ft_name_desc` (`name`, `description`);
 ALTER TABLE `conversations` ADD FULLTEXT KEY `ft_title` (`title`);
-ALTER TABLE `messages` ADD FULLTEXT KEY `ft_content` (`content`);
+-- 消息内容使用 ngram 分词，支持中文检索（分词长度由 ngram_token_size 决定，默认2）
+ALTER TABLE `messages` ADD FULLTEXT KEY `ft_content` (`content`) WITH PARSER ngram;
 
 
 
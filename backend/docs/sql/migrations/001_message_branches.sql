@@ -0,0 +1,16 @@
+-- 消息分支：为已有数据库的 messages 表增加 parent_id/branch/is_active
+-- 需要 MySQL 8.0 及以上（使用窗口函数回填 parent_id）
+
+ALTER TABLE `messages`
+  ADD COLUMN `parent_id` bigint(20) unsigned DEFAULT NULL COMMENT '上一条消息ID，为空表示对话的第一条' AFTER `conversation_id`,
+  ADD COLUMN `branch` int(11) NOT NULL DEFAULT '1' COMMENT '在同一父消息下的分支序号' AFTER `parent_id`,
+  ADD COLUMN `is_active` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否为兄弟消息中当前选中的分支' AFTER `branch`,
+  ADD KEY `idx_parent_id` (`conversation_id`,`parent_id`);
+
+-- 已有消息按时间顺序串成一条链
+UPDATE `messages` m
+JOIN (
+  SELECT `id`, LAG(`id`) OVER (PARTITION BY `conversation_id` ORDER BY `created_at`, `id`) AS `prev_id`
+  FROM `messages`
+) p ON m.`id` = p.`id`
+SET m.`parent_id` = p.`prev_id`;
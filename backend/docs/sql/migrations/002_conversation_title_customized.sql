@@ -0,0 +1,7 @@
+-- 自动标题：为已有数据库的 conversations 表增加 title_customized
+
+ALTER TABLE `conversations`
+  ADD COLUMN `title_customized` tinyint(1) NOT NULL DEFAULT '0' COMMENT '标题是否由用户设置，设置后不再自动生成' AFTER `title`;
+
+-- 已有的非默认标题视为用户设置，不再覆盖
+UPDATE `conversations` SET `title_customized` = 1 WHERE `title` <> '新对话';
@@ -0,0 +1,4 @@
+-- 角色工具：为已有数据库的 characters 表增加 tools
+
+ALTER TABLE `characters`
+  ADD COLUMN `tools` json DEFAULT NULL COMMENT '角色可使用的工具名称列表' AFTER `voice_settings`;
@@ -0,0 +1,25 @@
+-- 群聊：为已有数据库增加参与角色表、对话发言策略和消息发言角色
+
+ALTER TABLE `conversations`
+  ADD COLUMN `turn_policy` varchar(20) NOT NULL DEFAULT '' COMMENT '群聊发言策略：round_robin轮流 mention点名 auto模型选择，为空表示单角色对话' AFTER `title_customized`;
+
+ALTER TABLE `messages`
+  ADD COLUMN `character_id` bigint(20) unsigned DEFAULT NULL COMMENT '发言角色ID，AI消息记录由哪个角色回复' AFTER `type`;
+
+-- 已有的AI回复都由对话绑定的角色发言
+UPDATE `messages` m JOIN `conversations` c ON c.id = m.conversation_id
+SET m.character_id = c.character_id WHERE m.type = 'ai';
+
+CREATE TABLE `conversation_participants` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `position` int(11) NOT NULL DEFAULT '0' COMMENT '轮流发言的顺序，从0开始',
+  `status` tinyint(3) unsigned NOT NULL DEFAULT '1' COMMENT '状态：1正常 2已移出',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_conversation_character` (`conversation_id`,`character_id`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_participants_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_participants_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='群聊参与角色表';
@@ -0,0 +1,4 @@
+-- 内容审核：为已有数据库增加审核规则配置，已存在时不覆盖
+
+INSERT IGNORE INTO `system_configs` (`config_key`, `config_value`, `description`, `config_type`, `is_public`) VALUES
+('moderation_rules', '[{"type":"regex","pattern":"1[3-9]\\\\d{9}","action":"mask","reason":"手机号"}]', '内容审核规则：keyword/regex，处理方式 block/mask/flag', 'json', 0);
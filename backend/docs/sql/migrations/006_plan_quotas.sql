@@ -0,0 +1,26 @@
+-- 套餐和token额度：为已有数据库增加用户套餐表和用量表
+
+CREATE TABLE `user_plans` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `plan` varchar(20) NOT NULL DEFAULT 'free' COMMENT '套餐：free/pro，额度在对话服务配置中定义',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '到期时间，NULL表示长期有效，到期后按默认套餐计算',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`),
+  CONSTRAINT `fk_plans_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户套餐表';
+
+CREATE TABLE `user_token_usage` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `period_type` enum('day','month') NOT NULL COMMENT '周期类型：day按天 month按月',
+  `period` varchar(10) NOT NULL COMMENT '周期，如 2026-10-17、2026-10',
+  `tokens_used` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '已使用的token数',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_period` (`user_id`,`period_type`,`period`),
+  CONSTRAINT `fk_token_usage_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户token用量表';
@@ -0,0 +1,4 @@
+-- 备用模型提供方：为已有数据库的 characters 表增加 providers
+
+ALTER TABLE `characters`
+  ADD COLUMN `providers` json DEFAULT NULL COMMENT '备用模型提供方名称列表，按顺序尝试' AFTER `tools`;
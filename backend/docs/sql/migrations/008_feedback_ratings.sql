@@ -0,0 +1,33 @@
+-- 消息反馈和角色评分：为已有数据库增加反馈表和评分表
+
+CREATE TABLE `message_feedback` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `message_id` bigint(20) unsigned NOT NULL COMMENT 'AI消息ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `character_id` bigint(20) unsigned DEFAULT NULL COMMENT '回复的角色ID',
+  `vote` tinyint(4) NOT NULL COMMENT '评价：1赞 -1踩',
+  `reasons` json DEFAULT NULL COMMENT '原因标签列表',
+  `comment` varchar(500) NOT NULL DEFAULT '' COMMENT '补充说明',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_message_user` (`message_id`,`user_id`),
+  KEY `idx_character_vote` (`character_id`,`vote`),
+  KEY `idx_created_at` (`created_at`),
+  CONSTRAINT `fk_feedback_message` FOREIGN KEY (`message_id`) REFERENCES `messages` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_feedback_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息反馈表';
+
+CREATE TABLE `character_ratings` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `score` tinyint(3) unsigned NOT NULL COMMENT '评分：1-5星',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_character_user` (`character_id`,`user_id`),
+  CONSTRAINT `fk_ratings_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_ratings_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色评分表';
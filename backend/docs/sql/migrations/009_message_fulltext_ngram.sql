@@ -0,0 +1,5 @@
+-- 消息全文检索：把 messages.content 的全文索引改为 ngram 分词，支持中文
+-- 默认分词器按空格切词，中文整句被当作一个词，无法按关键词检索
+
+ALTER TABLE `messages` DROP INDEX `ft_content`;
+ALTER TABLE `messages` ADD FULLTEXT KEY `ft_content` (`content`) WITH PARSER ngram;
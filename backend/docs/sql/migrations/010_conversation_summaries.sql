@@ -0,0 +1,14 @@
+-- 对话摘要：为已有数据库增加摘要表，超出上下文窗口的历史消息压缩为滚动摘要
+
+CREATE TABLE `conversation_summaries` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '摘要ID',
+  `conversation_id` bigint(20) unsigned NOT NULL COMMENT '对话ID',
+  `summary` text NOT NULL COMMENT '摘要内容',
+  `covered_message_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '摘要覆盖到的最后一条消息ID',
+  `message_count` int(11) NOT NULL DEFAULT '0' COMMENT '摘要覆盖的消息数量',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_conversation_id` (`conversation_id`),
+  CONSTRAINT `fk_summaries_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='对话摘要表';
@@ -0,0 +1,18 @@
+-- 长期记忆：为已有数据库增加用户与角色之间的长期记忆表
+
+CREATE TABLE `user_character_memories` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '记忆ID',
+  `user_id` bigint(20) unsigned NOT NULL COMMENT '用户ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `content` varchar(500) NOT NULL COMMENT '记忆内容',
+  `importance` tinyint(3) unsigned NOT NULL DEFAULT '3' COMMENT '重要程度：1-5',
+  `source_message_id` bigint(20) unsigned DEFAULT NULL COMMENT '提取来源消息ID',
+  `status` tinyint(3) unsigned NOT NULL DEFAULT '1' COMMENT '状态：1正常 2已删除',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_character` (`user_id`,`character_id`,`status`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_memories_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_memories_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户角色长期记忆表';
@@ -0,0 +1,33 @@
+-- 角色知识库：为已有数据库增加知识库文档表和分块表
+
+CREATE TABLE `character_knowledge_documents` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '文档ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `title` varchar(200) NOT NULL COMMENT '文档标题',
+  `file_name` varchar(255) NOT NULL COMMENT '原始文件名',
+  `format` varchar(20) NOT NULL DEFAULT 'text' COMMENT '格式：text/markdown',
+  `size` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT '文件大小（字节）',
+  `chunk_count` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '分块数',
+  `embedder` varchar(100) NOT NULL COMMENT '嵌入模型',
+  `creator_id` bigint(20) unsigned NOT NULL COMMENT '上传者ID',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_character_id` (`character_id`),
+  CONSTRAINT `fk_knowledge_documents_character` FOREIGN KEY (`character_id`) REFERENCES `characters` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色知识库文档表';
+
+CREATE TABLE `character_knowledge_chunks` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '分块ID',
+  `document_id` bigint(20) unsigned NOT NULL COMMENT '文档ID',
+  `character_id` bigint(20) unsigned NOT NULL COMMENT '角色ID',
+  `chunk_index` int(10) unsigned NOT NULL COMMENT '分块在文档中的序号',
+  `content` text NOT NULL COMMENT '分块内容',
+  `embedder` varchar(100) NOT NULL COMMENT '嵌入模型',
+  `embedding` mediumblob NOT NULL COMMENT '向量（小端float32序列）',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_character_embedder` (`character_id`,`embedder`),
+  KEY `idx_document_id` (`document_id`),
+  CONSTRAINT `fk_knowledge_chunks_document` FOREIGN KEY (`document_id`) REFERENCES `character_knowledge_documents` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色知识库分块表';
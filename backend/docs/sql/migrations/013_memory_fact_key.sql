@@ -0,0 +1,6 @@
+-- 长期记忆按主题去重：为已有数据库的 user_character_memories 表增加 fact_key
+-- 已有记忆的主题为空，不参与替换，之后提取的同主题事实会覆盖旧事实
+
+ALTER TABLE `user_character_memories`
+  ADD COLUMN `fact_key` varchar(50) NOT NULL DEFAULT '' COMMENT '事实主题，同一主题只保留最新一条' AFTER `character_id`,
+  ADD KEY `idx_fact_key` (`user_id`,`character_id`,`fact_key`);
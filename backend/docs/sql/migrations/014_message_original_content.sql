@@ -0,0 +1,10 @@
+-- 内容审核：为已有数据库的 messages 表增加 original_content，审核前的原文不再保存在元数据中
+
+ALTER TABLE `messages`
+  ADD COLUMN `original_content` text DEFAULT NULL COMMENT '被遮蔽或拦截前的原文，仅管理员可见' AFTER `content`;
+
+-- 把已有消息元数据中的原文移到新列，并从元数据中删除
+UPDATE `messages`
+SET `original_content` = JSON_UNQUOTE(JSON_EXTRACT(`metadata`, '$.moderation.original')),
+    `metadata` = JSON_REMOVE(`metadata`, '$.moderation.original')
+WHERE JSON_EXTRACT(`metadata`, '$.moderation.original') IS NOT NULL;
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"ai-roleplay/common/knowledge"
 	common "ai-roleplay/common/utils"
 
 	"github.com/zeromicro/go-zero/rest"
@@ -10,4 +11,7 @@ type Config struct {
 	rest.RestConf
 	Mysql common.Config
 	Redis common.RedisCfg
+
+	// 角色知识库配置
+	Knowledge knowledge.Config
 }
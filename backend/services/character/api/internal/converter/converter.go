@@ -123,6 +123,8 @@ func (c *CharacterConverter) ToCharacterItem(character *model.Character) *types.
 		Prompt:        prompt,
 		Personality:   personality,
 		VoiceSettings: voiceSettings,
+		Tools:         character.GetTools(),
+		Providers:     character.GetProviders(),
 		Status:        character.Status,
 		IsPublic:      character.IsPublic == 1,
 		CreatorID:     creatorID,
@@ -0,0 +1,29 @@
+package public
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/character/api/internal/logic/public"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 获取角色知识库文档列表
+func GetKnowledgeListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.KnowledgeListRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := public.NewGetKnowledgeListLogic(r.Context(), svcCtx)
+		resp, err := l.GetKnowledgeList(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
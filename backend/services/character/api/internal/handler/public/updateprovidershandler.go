@@ -0,0 +1,29 @@
+package public
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/character/api/internal/logic/public"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 更新角色的备用模型提供方
+func UpdateProvidersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateProvidersRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := public.NewUpdateProvidersLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateProviders(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
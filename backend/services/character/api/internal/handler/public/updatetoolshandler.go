@@ -0,0 +1,29 @@
+package public
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/character/api/internal/logic/public"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 更新角色可使用的工具
+func UpdateToolsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateToolsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := public.NewUpdateToolsLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateTools(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
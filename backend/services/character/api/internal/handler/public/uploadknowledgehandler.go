@@ -0,0 +1,45 @@
+package public
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"ai-roleplay/services/character/api/internal/logic/public"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 上传角色知识库文档（multipart，字段 file）
+func UploadKnowledgeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UploadKnowledgeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, fmt.Errorf("读取上传文件失败: %w", err))
+			return
+		}
+		defer file.Close()
+
+		// 多读一个字节用于判断是否超过大小限制
+		content, err := io.ReadAll(io.LimitReader(file, svcCtx.Config.Knowledge.MaxFileSize+1))
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := public.NewUploadKnowledgeLogic(r.Context(), svcCtx)
+		resp, err := l.UploadKnowledge(&req, header.Filename, content)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
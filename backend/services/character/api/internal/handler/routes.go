@@ -45,6 +45,24 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/character/:id/favorite",
 				Handler: public.ToggleFavoriteHandler(serverCtx),
 			},
+			{
+				// 上传角色知识库文档（multipart，字段 file）
+				Method:  http.MethodPost,
+				Path:    "/api/character/:id/knowledge",
+				Handler: public.UploadKnowledgeHandler(serverCtx),
+			},
+			{
+				// 获取角色知识库文档列表
+				Method:  http.MethodGet,
+				Path:    "/api/character/:id/knowledge",
+				Handler: public.GetKnowledgeListHandler(serverCtx),
+			},
+			{
+				// 删除角色知识库文档
+				Method:  http.MethodDelete,
+				Path:    "/api/character/:id/knowledge/:doc_id",
+				Handler: public.DeleteKnowledgeHandler(serverCtx),
+			},
 			{
 				// 更新角色性格设置
 				Method:  http.MethodPut,
@@ -57,6 +75,18 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/character/:id/prompt",
 				Handler: public.UpdatePromptHandler(serverCtx),
 			},
+			{
+				// 更新角色的备用模型提供方
+				Method:  http.MethodPut,
+				Path:    "/api/character/:id/providers",
+				Handler: public.UpdateProvidersHandler(serverCtx),
+			},
+			{
+				// 更新角色可使用的工具
+				Method:  http.MethodPut,
+				Path:    "/api/character/:id/tools",
+				Handler: public.UpdateToolsHandler(serverCtx),
+			},
 			{
 				// 更新语音设置
 				Method:  http.MethodPut,
@@ -0,0 +1,95 @@
+package public
+
+import (
+	"ai-roleplay/services/character/api/internal/repo"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteKnowledgeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteKnowledgeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteKnowledgeLogic {
+	return &DeleteKnowledgeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteKnowledgeLogic) DeleteKnowledge(req *types.DeleteKnowledgeRequest) (resp *types.DeleteKnowledgeResponse, err error) {
+	// 参数验证
+	if req.ID <= 0 || req.DocID <= 0 {
+		return &types.DeleteKnowledgeResponse{
+			Code: 400,
+			Msg:  "参数无效",
+		}, nil
+	}
+
+	currentUserID := int64(1)
+
+	// 创建repo实例
+	characterRepo := repo.NewCharacterServiceRepo(l.ctx, l.svcCtx)
+
+	// 检查角色是否存在且有权限
+	existingCharacter, err := characterRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		l.Logger.Error("GetCharacterByID failed: ", err)
+		return &types.DeleteKnowledgeResponse{
+			Code: 500,
+			Msg:  "获取角色信息失败",
+		}, nil
+	}
+
+	if existingCharacter == nil {
+		return &types.DeleteKnowledgeResponse{
+			Code: 404,
+			Msg:  "角色不存在",
+		}, nil
+	}
+
+	if existingCharacter.CreatorID == nil || *existingCharacter.CreatorID != currentUserID {
+		return &types.DeleteKnowledgeResponse{
+			Code: 403,
+			Msg:  "无权限更新此角色",
+		}, nil
+	}
+
+	document, err := characterRepo.GetKnowledgeDocumentByID(req.ID, req.DocID)
+	if err != nil {
+		return &types.DeleteKnowledgeResponse{
+			Code: 500,
+			Msg:  "获取文档信息失败",
+		}, nil
+	}
+
+	if document == nil {
+		return &types.DeleteKnowledgeResponse{
+			Code: 404,
+			Msg:  "文档不存在",
+		}, nil
+	}
+
+	if err := characterRepo.DeleteKnowledgeDocument(document.ID); err != nil {
+		return &types.DeleteKnowledgeResponse{
+			Code: 500,
+			Msg:  "删除文档失败",
+		}, nil
+	}
+
+	// 已删除的内容不能再被检索到
+	if err := l.svcCtx.Knowledge.Invalidate(l.ctx, req.ID); err != nil {
+		l.Logger.Error("Invalidate knowledge cache failed: ", err)
+	}
+
+	return &types.DeleteKnowledgeResponse{
+		Code: 0,
+		Msg:  "删除成功",
+	}, nil
+}
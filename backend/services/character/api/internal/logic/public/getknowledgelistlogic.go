@@ -0,0 +1,83 @@
+package public
+
+import (
+	"ai-roleplay/services/character/api/internal/repo"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetKnowledgeListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetKnowledgeListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetKnowledgeListLogic {
+	return &GetKnowledgeListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetKnowledgeListLogic) GetKnowledgeList(req *types.KnowledgeListRequest) (resp *types.KnowledgeListResponse, err error) {
+	// 参数验证
+	if req.ID <= 0 {
+		return &types.KnowledgeListResponse{
+			Code: 400,
+			Msg:  "角色ID无效",
+		}, nil
+	}
+
+	currentUserID := int64(1)
+
+	// 创建repo实例
+	characterRepo := repo.NewCharacterServiceRepo(l.ctx, l.svcCtx)
+
+	// 检查角色是否存在且有权限
+	existingCharacter, err := characterRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		l.Logger.Error("GetCharacterByID failed: ", err)
+		return &types.KnowledgeListResponse{
+			Code: 500,
+			Msg:  "获取角色信息失败",
+		}, nil
+	}
+
+	if existingCharacter == nil {
+		return &types.KnowledgeListResponse{
+			Code: 404,
+			Msg:  "角色不存在",
+		}, nil
+	}
+
+	// 知识库资料只对角色创建者可见
+	if existingCharacter.CreatorID == nil || *existingCharacter.CreatorID != currentUserID {
+		return &types.KnowledgeListResponse{
+			Code: 403,
+			Msg:  "无权限查看此角色的知识库",
+		}, nil
+	}
+
+	documents, err := characterRepo.GetKnowledgeDocuments(req.ID)
+	if err != nil {
+		return &types.KnowledgeListResponse{
+			Code: 500,
+			Msg:  "获取知识库文档失败",
+		}, nil
+	}
+
+	list := make([]types.KnowledgeDocumentItem, 0, len(documents))
+	for i := range documents {
+		list = append(list, toKnowledgeDocumentItem(&documents[i]))
+	}
+
+	return &types.KnowledgeListResponse{
+		Code: 0,
+		Msg:  "获取成功",
+		List: list,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package public
+
+import (
+	"ai-roleplay/services/character/api/internal/repo"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxProviders 角色最多配置的备用提供方数
+const maxProviders = 5
+
+type UpdateProvidersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateProvidersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateProvidersLogic {
+	return &UpdateProvidersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateProvidersLogic) UpdateProviders(req *types.UpdateProvidersRequest) (resp *types.UpdateProvidersResponse, err error) {
+	// 参数验证
+	if req.ID <= 0 {
+		return &types.UpdateProvidersResponse{
+			Code: 400,
+			Msg:  "角色ID无效",
+		}, nil
+	}
+
+	// 提供方在对话服务中配置，这里只去掉空白和重复的名称，对话服务会忽略未配置的提供方
+	providers := make([]string, 0, len(req.Providers))
+	for _, name := range req.Providers {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(providers, name) {
+			providers = append(providers, name)
+		}
+	}
+	if len(providers) > maxProviders {
+		return &types.UpdateProvidersResponse{
+			Code: 400,
+			Msg:  "备用提供方最多5个",
+		}, nil
+	}
+
+	currentUserID := int64(1)
+
+	// 创建repo实例
+	characterRepo := repo.NewCharacterServiceRepo(l.ctx, l.svcCtx)
+
+	// 检查角色是否存在且有权限
+	existingCharacter, err := characterRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		l.Logger.Error("GetCharacterByID failed: ", err)
+		return &types.UpdateProvidersResponse{
+			Code: 500,
+			Msg:  "获取角色信息失败",
+		}, nil
+	}
+
+	if existingCharacter == nil {
+		return &types.UpdateProvidersResponse{
+			Code: 404,
+			Msg:  "角色不存在",
+		}, nil
+	}
+
+	// 权限检查：只能更新自己创建的角色
+	if existingCharacter.CreatorID == nil || *existingCharacter.CreatorID != currentUserID {
+		return &types.UpdateProvidersResponse{
+			Code: 403,
+			Msg:  "无权限更新此角色",
+		}, nil
+	}
+
+	providersJSON, err := json.Marshal(providers)
+	if err != nil {
+		l.Logger.Error("Marshal providers failed: ", err)
+		return &types.UpdateProvidersResponse{
+			Code: 500,
+			Msg:  "更新备用提供方失败",
+		}, nil
+	}
+
+	if err := characterRepo.UpdateProviders(req.ID, currentUserID, string(providersJSON)); err != nil {
+		l.Logger.Error("UpdateProviders failed: ", err)
+		return &types.UpdateProvidersResponse{
+			Code: 500,
+			Msg:  "更新备用提供方失败",
+		}, nil
+	}
+
+	return &types.UpdateProvidersResponse{
+		Code: 0,
+		Msg:  "更新成功",
+	}, nil
+}
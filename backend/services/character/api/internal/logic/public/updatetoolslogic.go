@@ -0,0 +1,103 @@
+package public
+
+import (
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/character/api/internal/repo"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateToolsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateToolsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateToolsLogic {
+	return &UpdateToolsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateToolsLogic) UpdateTools(req *types.UpdateToolsRequest) (resp *types.UpdateToolsResponse, err error) {
+	// 参数验证
+	if req.ID <= 0 {
+		return &types.UpdateToolsResponse{
+			Code: 400,
+			Msg:  "角色ID无效",
+		}, nil
+	}
+
+	// 只允许内置工具，重复的名称只保留一个
+	tools := make([]string, 0, len(req.Tools))
+	for _, name := range req.Tools {
+		if !slices.Contains(common.AI_Tools, name) {
+			return &types.UpdateToolsResponse{
+				Code: 400,
+				Msg:  "不支持的工具: " + name,
+			}, nil
+		}
+		if !slices.Contains(tools, name) {
+			tools = append(tools, name)
+		}
+	}
+
+	currentUserID := int64(1)
+
+	// 创建repo实例
+	characterRepo := repo.NewCharacterServiceRepo(l.ctx, l.svcCtx)
+
+	// 检查角色是否存在且有权限
+	existingCharacter, err := characterRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		l.Logger.Error("GetCharacterByID failed: ", err)
+		return &types.UpdateToolsResponse{
+			Code: 500,
+			Msg:  "获取角色信息失败",
+		}, nil
+	}
+
+	if existingCharacter == nil {
+		return &types.UpdateToolsResponse{
+			Code: 404,
+			Msg:  "角色不存在",
+		}, nil
+	}
+
+	// 权限检查：只能更新自己创建的角色
+	if existingCharacter.CreatorID == nil || *existingCharacter.CreatorID != currentUserID {
+		return &types.UpdateToolsResponse{
+			Code: 403,
+			Msg:  "无权限更新此角色",
+		}, nil
+	}
+
+	toolsJSON, err := json.Marshal(tools)
+	if err != nil {
+		l.Logger.Error("Marshal tools failed: ", err)
+		return &types.UpdateToolsResponse{
+			Code: 500,
+			Msg:  "更新工具失败",
+		}, nil
+	}
+
+	if err := characterRepo.UpdateTools(req.ID, currentUserID, string(toolsJSON)); err != nil {
+		l.Logger.Error("UpdateTools failed: ", err)
+		return &types.UpdateToolsResponse{
+			Code: 500,
+			Msg:  "更新工具失败",
+		}, nil
+	}
+
+	return &types.UpdateToolsResponse{
+		Code: 0,
+		Msg:  "更新成功",
+	}, nil
+}
@@ -0,0 +1,185 @@
+package public
+
+import (
+	"ai-roleplay/common/knowledge"
+	"ai-roleplay/services/character/api/internal/repo"
+	"ai-roleplay/services/character/api/internal/svc"
+	"ai-roleplay/services/character/api/internal/types"
+	"ai-roleplay/services/character/model"
+	"context"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// embedBatchSize 每次调用嵌入模型的分块数
+const embedBatchSize = 32
+
+type UploadKnowledgeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUploadKnowledgeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadKnowledgeLogic {
+	return &UploadKnowledgeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UploadKnowledgeLogic) UploadKnowledge(req *types.UploadKnowledgeRequest, fileName string, content []byte) (resp *types.UploadKnowledgeResponse, err error) {
+	// 参数验证
+	if req.ID <= 0 {
+		return &types.UploadKnowledgeResponse{
+			Code: 400,
+			Msg:  "角色ID无效",
+		}, nil
+	}
+
+	format := knowledgeFormat(fileName)
+	if format == "" {
+		return &types.UploadKnowledgeResponse{
+			Code: 400,
+			Msg:  "仅支持 txt 和 markdown 文件",
+		}, nil
+	}
+
+	if int64(len(content)) > l.svcCtx.Config.Knowledge.MaxFileSize {
+		return &types.UploadKnowledgeResponse{
+			Code: 400,
+			Msg:  "文件过大",
+		}, nil
+	}
+
+	if !utf8.Valid(content) {
+		return &types.UploadKnowledgeResponse{
+			Code: 400,
+			Msg:  "文件必须是 UTF-8 编码的文本",
+		}, nil
+	}
+
+	currentUserID := int64(1)
+
+	// 创建repo实例
+	characterRepo := repo.NewCharacterServiceRepo(l.ctx, l.svcCtx)
+
+	// 检查角色是否存在且有权限
+	existingCharacter, err := characterRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		l.Logger.Error("GetCharacterByID failed: ", err)
+		return &types.UploadKnowledgeResponse{
+			Code: 500,
+			Msg:  "获取角色信息失败",
+		}, nil
+	}
+
+	if existingCharacter == nil {
+		return &types.UploadKnowledgeResponse{
+			Code: 404,
+			Msg:  "角色不存在",
+		}, nil
+	}
+
+	// 权限检查：只能为自己创建的角色上传资料
+	if existingCharacter.CreatorID == nil || *existingCharacter.CreatorID != currentUserID {
+		return &types.UploadKnowledgeResponse{
+			Code: 403,
+			Msg:  "无权限更新此角色",
+		}, nil
+	}
+
+	// 切分并计算向量
+	config := l.svcCtx.Config.Knowledge
+	texts := knowledge.NewChunker(config.ChunkSize, config.ChunkOverlap).Split(string(content))
+	if len(texts) == 0 {
+		return &types.UploadKnowledgeResponse{
+			Code: 400,
+			Msg:  "文件内容为空",
+		}, nil
+	}
+
+	embedder := l.svcCtx.Embedder
+	chunks := make([]*model.KnowledgeChunk, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		vectors, err := embedder.Embed(l.ctx, texts[start:end])
+		if err != nil {
+			l.Logger.Error("Embed knowledge failed: ", err)
+			return &types.UploadKnowledgeResponse{
+				Code: 500,
+				Msg:  "生成文档向量失败",
+			}, nil
+		}
+		for i, vector := range vectors {
+			chunks = append(chunks, &model.KnowledgeChunk{
+				CharacterID: req.ID,
+				ChunkIndex:  int32(start + i),
+				Content:     texts[start+i],
+				Embedder:    embedder.Name(),
+				Embedding:   knowledge.EncodeVector(vector),
+			})
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	}
+	document := &model.KnowledgeDocument{
+		CharacterID: req.ID,
+		Title:       title,
+		FileName:    filepath.Base(fileName),
+		Format:      format,
+		Size:        int64(len(content)),
+		ChunkCount:  int32(len(chunks)),
+		Embedder:    embedder.Name(),
+		CreatorID:   currentUserID,
+	}
+	if err := characterRepo.CreateKnowledgeDocument(document, chunks); err != nil {
+		return &types.UploadKnowledgeResponse{
+			Code: 500,
+			Msg:  "保存文档失败",
+		}, nil
+	}
+
+	if err := l.svcCtx.Knowledge.Invalidate(l.ctx, req.ID); err != nil {
+		l.Logger.Error("Invalidate knowledge cache failed: ", err)
+	}
+
+	l.Logger.Infof("Knowledge uploaded - character_id: %d, document_id: %d, chunks: %d", req.ID, document.ID, len(chunks))
+
+	return &types.UploadKnowledgeResponse{
+		Code:     0,
+		Msg:      "上传成功",
+		Document: toKnowledgeDocumentItem(document),
+	}, nil
+}
+
+// knowledgeFormat 根据扩展名判断文档格式，不支持时返回空
+func knowledgeFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".txt":
+		return "text"
+	case ".md", ".markdown":
+		return "markdown"
+	default:
+		return ""
+	}
+}
+
+func toKnowledgeDocumentItem(document *model.KnowledgeDocument) types.KnowledgeDocumentItem {
+	return types.KnowledgeDocumentItem{
+		ID:         document.ID,
+		Title:      document.Title,
+		FileName:   document.FileName,
+		Format:     document.Format,
+		Size:       document.Size,
+		ChunkCount: int(document.ChunkCount),
+		Embedder:   document.Embedder,
+		CreatedAt:  document.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -440,6 +440,34 @@ func (r *CharacterServiceRepo) UpdatePrompt(id, creatorID int64, prompt string)
 	return nil
 }
 
+// UpdateTools 更新角色可使用的工具
+func (r *CharacterServiceRepo) UpdateTools(id, creatorID int64, tools string) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Model(&model.Character{}).
+		Where("id = ? AND creator_id = ?", id, creatorID).
+		Update("tools", tools).Error; err != nil {
+		r.Logger.Error("UpdateTools failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateProviders 更新角色的备用模型提供方
+func (r *CharacterServiceRepo) UpdateProviders(id, creatorID int64, providers string) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Model(&model.Character{}).
+		Where("id = ? AND creator_id = ?", id, creatorID).
+		Update("providers", providers).Error; err != nil {
+		r.Logger.Error("UpdateProviders failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
 // UpdatePersonality 更新性格设置
 func (r *CharacterServiceRepo) UpdatePersonality(id, creatorID int64, personality string) error {
 	db := r.svcCtx.Db.WithContext(r.ctx)
@@ -467,3 +495,76 @@ func (r *CharacterServiceRepo) UpdateVoiceSettings(id, creatorID int64, voiceSet
 
 	return nil
 }
+
+// CreateKnowledgeDocument 在同一事务中保存文档及其分块
+func (r *CharacterServiceRepo) CreateKnowledgeDocument(document *model.KnowledgeDocument, chunks []*model.KnowledgeChunk) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(document).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		for _, chunk := range chunks {
+			chunk.DocumentID = document.ID
+		}
+		return tx.CreateInBatches(chunks, 100).Error
+	})
+	if err != nil {
+		r.Logger.Error("CreateKnowledgeDocument failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetKnowledgeDocuments 获取角色的知识库文档
+func (r *CharacterServiceRepo) GetKnowledgeDocuments(characterID int64) ([]model.KnowledgeDocument, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var documents []model.KnowledgeDocument
+	if err := db.Where("character_id = ?", characterID).
+		Order("created_at DESC, id DESC").
+		Find(&documents).Error; err != nil {
+		r.Logger.Error("GetKnowledgeDocuments failed: ", err)
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// GetKnowledgeDocumentByID 获取角色的单个知识库文档
+func (r *CharacterServiceRepo) GetKnowledgeDocumentByID(characterID, documentID int64) (*model.KnowledgeDocument, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var document model.KnowledgeDocument
+	if err := db.Where("id = ? AND character_id = ?", documentID, characterID).First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.Logger.Error("GetKnowledgeDocumentByID failed: ", err)
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// DeleteKnowledgeDocument 删除文档及其分块
+func (r *CharacterServiceRepo) DeleteKnowledgeDocument(documentID int64) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", documentID).Delete(&model.KnowledgeChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.KnowledgeDocument{}, documentID).Error
+	})
+	if err != nil {
+		r.Logger.Error("DeleteKnowledgeDocument failed: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,23 +1,36 @@
 package svc
 
 import (
+	"ai-roleplay/common/knowledge"
 	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/character/api/internal/config"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
 )
 
 type ServiceContext struct {
-	Config config.Config
-	Db     *gorm.DB
-	Redis  *redis.Client
+	Config   config.Config
+	Db       *gorm.DB
+	Redis    *redis.Client
+	Embedder knowledge.Embedder
+
+	// 文档变更后通过它通知对话服务丢弃缓存的向量索引
+	Knowledge *knowledge.Retriever
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	embedder, err := knowledge.NewEmbedder(c.Knowledge.Embedder)
+	logx.Must(err)
+
+	db := common.GetDB(c.Mysql)
+	rdb := common.GetRedis(c.Redis)
 	return &ServiceContext{
-		Config: c,
-		Db:     common.GetDB(c.Mysql),
-		Redis:  common.GetRedis(c.Redis),
+		Config:    c,
+		Db:        db,
+		Redis:     rdb,
+		Embedder:  embedder,
+		Knowledge: knowledge.NewRetriever(db, embedder, knowledge.NewRedisVersions(rdb), c.Knowledge.CacheTTL),
 	}
 }
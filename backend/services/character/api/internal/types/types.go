@@ -50,6 +50,8 @@ type CharacterItem struct {
 	Prompt        string                 `json:"prompt"`         // 角色提示词
 	Personality   CharacterPersonality   `json:"personality"`    // 性格设置
 	VoiceSettings CharacterVoiceSettings `json:"voice_settings"` // 语音设置
+	Tools         []string               `json:"tools"`          // 角色可使用的工具
+	Providers     []string               `json:"providers"`      // 备用模型提供方，按顺序尝试
 	Status        int32                  `json:"status"`         // 状态：1正常 2禁用
 	IsPublic      bool                   `json:"is_public"`      // 是否公开：true公开 false私有
 	CreatorID     int64                  `json:"creator_id"`     // 创建者ID，0表示系统预设
@@ -135,6 +137,16 @@ type DeleteCharacterResponse struct {
 	Msg  string `json:"msg"`  // 响应消息
 }
 
+type DeleteKnowledgeRequest struct {
+	ID    int64 `path:"id"`     // 角色ID
+	DocID int64 `path:"doc_id"` // 文档ID
+}
+
+type DeleteKnowledgeResponse struct {
+	Code int    `json:"code"` // 响应码
+	Msg  string `json:"msg"`  // 响应消息
+}
+
 type FavoriteCharacterRequest struct {
 	Page     int `form:"page,optional,default=1"`       // 页码
 	PageSize int `form:"page_size,optional,default=20"` // 每页条数
@@ -148,6 +160,27 @@ type FavoriteCharacterResponse struct {
 	List  []CharacterBrief `json:"list"`  // 角色列表
 }
 
+type KnowledgeDocumentItem struct {
+	ID         int64  `json:"id"`          // 文档ID
+	Title      string `json:"title"`       // 文档标题
+	FileName   string `json:"file_name"`   // 原始文件名
+	Format     string `json:"format"`      // 格式：text/markdown
+	Size       int64  `json:"size"`        // 文件大小（字节）
+	ChunkCount int    `json:"chunk_count"` // 分块数
+	Embedder   string `json:"embedder"`    // 嵌入模型
+	CreatedAt  string `json:"created_at"`  // 上传时间
+}
+
+type KnowledgeListRequest struct {
+	ID int64 `path:"id"` // 角色ID
+}
+
+type KnowledgeListResponse struct {
+	Code int                     `json:"code"` // 响应码
+	Msg  string                  `json:"msg"`  // 响应消息
+	List []KnowledgeDocumentItem `json:"list"` // 文档列表
+}
+
 type MyCharacterRequest struct {
 	Page     int `form:"page,optional,default=1"`       // 页码
 	PageSize int `form:"page_size,optional,default=20"` // 每页条数
@@ -258,6 +291,26 @@ type UpdatePromptResponse struct {
 	Msg  string `json:"msg"`  // 响应消息
 }
 
+type UpdateProvidersRequest struct {
+	ID        int64    `path:"id"`        // 角色ID
+	Providers []string `json:"providers"` // 提供方名称列表，为空表示使用全局配置
+}
+
+type UpdateProvidersResponse struct {
+	Code int    `json:"code"` // 响应码
+	Msg  string `json:"msg"`  // 响应消息
+}
+
+type UpdateToolsRequest struct {
+	ID    int64    `path:"id"`    // 角色ID
+	Tools []string `json:"tools"` // 工具名称列表，为空表示不使用工具
+}
+
+type UpdateToolsResponse struct {
+	Code int    `json:"code"` // 响应码
+	Msg  string `json:"msg"`  // 响应消息
+}
+
 type UpdateVoiceSettingsRequest struct {
 	ID            int64                  `path:"id"`             // 角色ID
 	VoiceSettings CharacterVoiceSettings `json:"voice_settings"` // 语音设置
@@ -267,3 +320,14 @@ type UpdateVoiceSettingsResponse struct {
 	Code int    `json:"code"` // 响应码
 	Msg  string `json:"msg"`  // 响应消息
 }
+
+type UploadKnowledgeRequest struct {
+	ID    int64  `path:"id"`             // 角色ID
+	Title string `form:"title,optional"` // 文档标题，默认使用文件名
+}
+
+type UploadKnowledgeResponse struct {
+	Code     int                   `json:"code"`     // 响应码
+	Msg      string                `json:"msg"`      // 响应消息
+	Document KnowledgeDocumentItem `json:"document"` // 上传的文档
+}
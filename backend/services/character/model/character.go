@@ -16,6 +16,8 @@ type Character struct {
 	Prompt        *string   `gorm:"column:prompt" json:"prompt"`
 	Personality   *string   `gorm:"column:personality" json:"personality"`
 	VoiceSettings *string   `gorm:"column:voice_settings" json:"voice_settings"`
+	Tools         *string   `gorm:"column:tools" json:"tools"`         // JSON数组，角色可使用的工具名称
+	Providers     *string   `gorm:"column:providers" json:"providers"` // JSON数组，角色的备用模型提供方
 	Status        int32     `gorm:"column:status" json:"status"`
 	IsPublic      int32     `gorm:"column:is_public" json:"is_public"`
 	CreatorID     *int64    `gorm:"column:creator_id" json:"creator_id"`
@@ -41,3 +43,25 @@ func (c *Character) GetTags() []string {
 	json.Unmarshal([]byte(*c.Tags), &tags)
 	return tags
 }
+
+// GetTools 返回角色可使用的工具名称
+func (c *Character) GetTools() []string {
+	if c.Tools == nil {
+		return []string{}
+	}
+
+	var tools []string
+	json.Unmarshal([]byte(*c.Tools), &tools)
+	return tools
+}
+
+// GetProviders 返回角色的备用模型提供方，按顺序尝试
+func (c *Character) GetProviders() []string {
+	if c.Providers == nil {
+		return []string{}
+	}
+
+	var providers []string
+	json.Unmarshal([]byte(*c.Providers), &providers)
+	return providers
+}
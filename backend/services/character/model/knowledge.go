@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// KnowledgeDocument 角色知识库文档
+type KnowledgeDocument struct {
+	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
+	CharacterID int64     `gorm:"column:character_id" json:"character_id"`
+	Title       string    `gorm:"column:title" json:"title"`
+	FileName    string    `gorm:"column:file_name" json:"file_name"`
+	Format      string    `gorm:"column:format" json:"format"` // 'text', 'markdown'
+	Size        int64     `gorm:"column:size" json:"size"`     // 字节
+	ChunkCount  int32     `gorm:"column:chunk_count" json:"chunk_count"`
+	Embedder    string    `gorm:"column:embedder" json:"embedder"`
+	CreatorID   int64     `gorm:"column:creator_id" json:"creator_id"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (KnowledgeDocument) TableName() string {
+	return "character_knowledge_documents"
+}
+
+// KnowledgeChunk 文档分块及其向量
+type KnowledgeChunk struct {
+	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
+	DocumentID  int64     `gorm:"column:document_id" json:"document_id"`
+	CharacterID int64     `gorm:"column:character_id" json:"character_id"`
+	ChunkIndex  int32     `gorm:"column:chunk_index" json:"chunk_index"`
+	Content     string    `gorm:"column:content" json:"content"`
+	Embedder    string    `gorm:"column:embedder" json:"embedder"`
+	Embedding   []byte    `gorm:"column:embedding" json:"-"` // 小端 float32 序列
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (KnowledgeChunk) TableName() string {
+	return "character_knowledge_chunks"
+}
@@ -1,7 +1,13 @@
 package config
 
 import (
+	"slices"
+	"time"
+
+	"ai-roleplay/common/knowledge"
 	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/moderation"
+	"ai-roleplay/services/chat/api/internal/quota"
 
 	"github.com/zeromicro/go-zero/rest"
 )
@@ -10,4 +16,154 @@ type Config struct {
 	rest.RestConf
 	Mysql common.Config
 	Redis common.RedisCfg
+
+	// 大模型配置
+	LLM LLMConfig
+
+	// 对话摘要配置
+	Summary SummaryConfig
+
+	// 长期记忆配置
+	Memory MemoryConfig
+
+	// 自动标题配置
+	Title TitleConfig
+
+	// 角色知识库检索配置，Embedder 需与角色服务保持一致
+	Knowledge knowledge.Config
+
+	// 流式生成配置
+	Generation GenerationConfig
+
+	// WebSocket 聊天通道配置
+	WebSocket WebSocketConfig
+
+	// 工具调用配置
+	Tools ToolsConfig
+
+	// 群聊配置
+	Group GroupConfig
+
+	// 内容审核配置
+	Moderation moderation.Config
+
+	// 发送消息的限流配置
+	RateLimit RateLimitConfig
+
+	// 套餐额度配置
+	Quota quota.Config
+
+	// 管理员配置
+	Admin AdminConfig `json:",optional"`
+}
+
+// 管理员配置，管理员可查看全部用户的审核记录和反馈
+type AdminConfig struct {
+	UserIDs []int64 `json:",optional"`
+}
+
+// IsAdmin 判断用户是否为管理员
+func (c AdminConfig) IsAdmin(userID int64) bool {
+	return slices.Contains(c.UserIDs, userID)
+}
+
+// 流式生成配置
+type GenerationConfig struct {
+	MaxDuration time.Duration `json:",default=3m"`  // 单次生成的最长时间，超时后中断
+	EventTTL    time.Duration `json:",default=10m"` // 事件缓冲的保留时间，客户端在此期间可断线续传
+}
+
+// WebSocket 聊天通道配置
+type WebSocketConfig struct {
+	AllowedOrigins []string `json:",optional"` // 允许建立连接的页面来源，如 https://example.com，* 表示不限；同源请求始终允许
+}
+
+// 发送消息的限流配置，按用户和IP分别计算，超出后需等待令牌补充
+type RateLimitConfig struct {
+	Enable     bool    `json:",default=true"`
+	UserRate   float64 `json:",default=0.2"` // 每个用户每秒补充的请求数
+	UserBurst  int     `json:",default=10"`  // 每个用户允许的突发请求数
+	IPRate     float64 `json:",default=0.5"` // 每个IP每秒补充的请求数
+	IPBurst    int     `json:",default=20"`  // 每个IP允许的突发请求数
+	MaxStreams int     `json:",default=2"`   // 每个用户同时进行的生成数
+
+	TrustedProxies []string `json:",optional"` // 可信反向代理的网段或IP，只有来自这些地址的请求才采信 X-Forwarded-For
+}
+
+// 工具调用配置
+type ToolsConfig struct {
+	Enable    bool `json:",default=true"`
+	MaxRounds int  `json:",default=4"` // 单轮回复中最多调用工具的次数，达到后要求模型直接回答
+}
+
+// 群聊配置
+type GroupConfig struct {
+	MaxMembers int           `json:",default=8"`   // 一个群聊最多邀请的角色数
+	Provider   string        `json:",optional"`    // auto 策略选择发言角色使用的模型提供方，为空时使用默认
+	Timeout    time.Duration `json:",default=10s"` // 选择发言角色的最长时间，超时后改为轮流
+}
+
+// LLM配置
+type LLMConfig struct {
+	Default           string           // 默认使用的提供方名称
+	Providers         []ProviderConfig // 可用的提供方列表
+	Fallbacks         []string         `json:",optional"`    // 备用提供方，调用失败或首个分片超时时按顺序切换；角色配置了备用提供方时优先使用角色的
+	FirstTokenTimeout time.Duration    `json:",default=15s"` // 等待首个分片的最长时间，超时后切换到下一个提供方
+}
+
+// 对话摘要配置
+type SummaryConfig struct {
+	Enable     bool   `json:",default=true"`
+	Interval   int    `json:",default=10"` // 累计多少条待摘要消息后触发一次摘要
+	KeepRecent int    `json:",default=20"` // 最近多少条消息保留原文，不参与摘要
+	Provider   string `json:",optional"`   // 摘要使用的模型提供方，为空时使用默认
+}
+
+// 长期记忆配置
+type MemoryConfig struct {
+	Enable        bool   `json:",default=true"`
+	MaxMemories   int    `json:",default=100"` // 每个用户和角色之间最多保留的记忆数
+	RetrieveLimit int    `json:",default=8"`   // 每轮对话注入提示词的记忆数
+	Provider      string `json:",optional"`    // 提取记忆使用的模型提供方，为空时使用默认
+}
+
+// 自动标题配置
+type TitleConfig struct {
+	Enable   bool          `json:",default=true"`
+	Provider string        `json:",optional"`    // 生成标题使用的模型提供方，为空时使用默认
+	Timeout  time.Duration `json:",default=15s"` // 等待标题生成的最长时间，超时后不再推送
+}
+
+// 模型提供方配置
+type ProviderConfig struct {
+	Name          string        // 提供方名称，请求中按此名称选择
+	Type          string        // openai, ollama, mock
+	BaseURL       string        `json:",optional"`
+	Model         string        `json:",optional"`
+	APIKey        string        `json:",optional"`
+	APIKeyEnv     string        `json:",optional"` // 从环境变量读取密钥，优先于 APIKey
+	Timeout       time.Duration `json:",default=60s"`
+	Temperature   float32       `json:",optional"`
+	MaxTokens     int           `json:",optional"`
+	ContextWindow int           `json:",default=8192"`      // 模型上下文窗口大小（token）
+	Tokenizer     string        `json:",default=heuristic"` // 历史裁剪使用的分词器：heuristic, rune
+	Mock          MockConfig    `json:",optional"`          // 仅 mock 类型使用
+}
+
+// 模拟模型配置
+type MockConfig struct {
+	ChunkSize    int           `json:",default=4"` // 每个流式分片的字符数
+	Latency      time.Duration `json:",optional"`  // 分片之间的间隔
+	DefaultReply string        `json:",optional"`  // 未命中规则时的回复，为空则回显用户消息
+	Rules        []MockRule    `json:",optional"`
+}
+
+// 模拟模型规则，用户消息包含关键字时触发，按顺序匹配第一条
+type MockRule struct {
+	Keyword    string
+	Reply      string `json:",optional"`
+	Error      string `json:",optional"` // 返回的错误信息
+	ErrorAfter int    `json:",optional"` // 输出多少个分片后返回错误，0 表示直接失败，超过分片数时在最后一个分片后失败
+	ToolName   string `json:",optional"` // 返回对该工具的调用
+	ToolArgs   string `json:",optional"` // 工具调用参数（JSON）
 }
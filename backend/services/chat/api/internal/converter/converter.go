@@ -1,11 +1,10 @@
 package converter
 
 import (
+	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/chat/api/internal/types"
 	"ai-roleplay/services/chat/model"
 	"encoding/json"
-	"fmt"
-	"strings"
 	"time"
 )
 
@@ -23,13 +22,24 @@ func (c *ChatConverter) ToMessage(message *model.Message) *types.Message {
 		return nil
 	}
 
-	return &types.Message{
+	result := &types.Message{
 		ID:             message.ID,
 		ConversationID: message.ConversationID,
 		Type:           message.Type,
 		Content:        message.Content,
 		Timestamp:      message.CreatedAt.Format("2006-01-02 15:04:05"),
+		Branch:         int(message.Branch),
 	}
+	if message.ParentID != nil {
+		result.ParentID = *message.ParentID
+	}
+	if message.CharacterID != nil {
+		result.CharacterID = *message.CharacterID
+	}
+	if message.Metadata != nil {
+		result.Metadata = *message.Metadata
+	}
+	return result
 }
 
 // ToMessageList 将数据库模型列表转换为API消息列表
@@ -43,6 +53,17 @@ func (c *ChatConverter) ToMessageList(messages []model.Message) []types.Message
 	return result
 }
 
+// ToBranchMessageList 转换当前分支路径上的消息，存在多个分支的消息附带兄弟消息ID，便于前端切换
+func (c *ChatConverter) ToBranchMessageList(path []model.Message, siblings map[int64][]int64) []types.Message {
+	result := c.ToMessageList(path)
+	for i := range result {
+		if ids := siblings[result[i].ParentID]; len(ids) > 1 {
+			result[i].Siblings = ids
+		}
+	}
+	return result
+}
+
 // ToConversation 将数据库模型转换为API对话类型
 func (c *ChatConverter) ToConversation(conversation *model.Conversation) *types.Conversation {
 	if conversation == nil {
@@ -63,6 +84,7 @@ func (c *ChatConverter) ToConversation(conversation *model.Conversation) *types.
 		LastMessageTime: conversation.UpdatedAt.Format("2006-01-02 15:04:05"),
 		MessageCount:    0, // 需要单独计算
 		Status:          int(conversation.Status),
+		TurnPolicy:      conversation.TurnPolicy,
 	}
 }
 
@@ -172,187 +194,6 @@ func (c *ChatConverter) BuildBaseResponse(code int, msg string) *types.BaseRespo
 	}
 }
 
-// BuildExportResponse 构建导出响应
-func (c *ChatConverter) BuildExportResponse(conversation *model.Conversation, messages []model.Message) *types.ExportResponse {
-	if conversation == nil {
-		return &types.ExportResponse{
-			Code: 404,
-			Msg:  "对话不存在",
-		}
-	}
-
-	// 构建导出内容
-	var content strings.Builder
-
-	// 文件头信息
-	content.WriteString("=====================================\n")
-	content.WriteString("        AI 角色扮演对话记录\n")
-	content.WriteString("=====================================\n\n")
-
-	// 对话基本信息
-	content.WriteString("对话信息:\n")
-	content.WriteString("--------\n")
-	content.WriteString(fmt.Sprintf("对话ID: %d\n", conversation.ID))
-	content.WriteString(fmt.Sprintf("对话标题: %s\n", conversation.Title))
-	content.WriteString(fmt.Sprintf("角色ID: %d\n", conversation.CharacterID))
-
-	if conversation.UserID != nil {
-		content.WriteString(fmt.Sprintf("用户ID: %d\n", *conversation.UserID))
-	}
-
-	content.WriteString(fmt.Sprintf("创建时间: %s\n", conversation.CreatedAt.Format("2006年01月02日 15:04:05")))
-	content.WriteString(fmt.Sprintf("最后更新: %s\n", conversation.UpdatedAt.Format("2006年01月02日 15:04:05")))
-	content.WriteString(fmt.Sprintf("消息总数: %d条\n", len(messages)))
-
-	// 计算对话时长
-	duration := conversation.UpdatedAt.Sub(conversation.CreatedAt)
-	if duration.Hours() >= 24 {
-		content.WriteString(fmt.Sprintf("对话时长: %.1f天\n", duration.Hours()/24))
-	} else if duration.Hours() >= 1 {
-		content.WriteString(fmt.Sprintf("对话时长: %.1f小时\n", duration.Hours()))
-	} else {
-		content.WriteString(fmt.Sprintf("对话时长: %.0f分钟\n", duration.Minutes()))
-	}
-
-	// 统计信息
-	var totalTokens int32
-	var totalProcessingTime int32
-	userMessageCount := 0
-	aiMessageCount := 0
-
-	for _, message := range messages {
-		if message.Type == "user" {
-			userMessageCount++
-		} else {
-			aiMessageCount++
-			totalTokens += message.TokenUsed
-			totalProcessingTime += message.ProcessingTime
-		}
-	}
-
-	content.WriteString(fmt.Sprintf("用户消息: %d条\n", userMessageCount))
-	content.WriteString(fmt.Sprintf("AI消息: %d条\n", aiMessageCount))
-	content.WriteString(fmt.Sprintf("总Token消耗: %d\n", totalTokens))
-	content.WriteString(fmt.Sprintf("总处理时间: %.2f秒\n", float64(totalProcessingTime)/1000))
-
-	if aiMessageCount > 0 {
-		avgTokens := float64(totalTokens) / float64(aiMessageCount)
-		avgProcessingTime := float64(totalProcessingTime) / float64(aiMessageCount)
-		content.WriteString(fmt.Sprintf("平均Token/消息: %.1f\n", avgTokens))
-		content.WriteString(fmt.Sprintf("平均处理时间: %.0f毫秒\n", avgProcessingTime))
-	}
-
-	content.WriteString("\n")
-
-	// 对话内容
-	content.WriteString("对话内容:\n")
-	content.WriteString("--------\n\n")
-
-	if len(messages) == 0 {
-		content.WriteString("暂无对话消息\n")
-	} else {
-		for i, message := range messages {
-			// 消息序号
-			content.WriteString(fmt.Sprintf("[%d] ", i+1))
-
-			// 发送者标识
-			var sender string
-			var senderIcon string
-			if message.Type == "user" {
-				sender = "用户"
-				senderIcon = "👤"
-			} else {
-				sender = "AI助手"
-				senderIcon = "🤖"
-			}
-
-			// 时间戳
-			timestamp := message.CreatedAt.Format("15:04:05")
-
-			// 消息头
-			content.WriteString(fmt.Sprintf("%s %s (%s)", senderIcon, sender, timestamp))
-
-			// AI消息的额外信息
-			if message.Type == "ai" {
-				if message.TokenUsed > 0 || message.ProcessingTime > 0 {
-					content.WriteString(" [")
-					if message.TokenUsed > 0 {
-						content.WriteString(fmt.Sprintf("Token: %d", message.TokenUsed))
-					}
-					if message.ProcessingTime > 0 {
-						if message.TokenUsed > 0 {
-							content.WriteString(", ")
-						}
-						content.WriteString(fmt.Sprintf("耗时: %dms", message.ProcessingTime))
-					}
-					content.WriteString("]")
-				}
-			}
-
-			content.WriteString("\n")
-
-			// 消息内容（处理多行文本）
-			messageLines := strings.Split(message.Content, "\n")
-			for _, line := range messageLines {
-				content.WriteString(fmt.Sprintf("    %s\n", line))
-			}
-
-			// 音频信息
-			if message.AudioID != nil {
-				content.WriteString(fmt.Sprintf("    🔊 语音消息 (音频ID: %d)\n", *message.AudioID))
-			}
-
-			// 元数据信息
-			if message.Metadata != nil {
-				metadata, err := c.GetMessageMetadata(&message)
-				if err == nil && len(metadata) > 0 {
-					content.WriteString("    📋 元数据: ")
-					for key, value := range metadata {
-						content.WriteString(fmt.Sprintf("%s=%v ", key, value))
-					}
-					content.WriteString("\n")
-				}
-			}
-
-			// 消息间分隔
-			if i < len(messages)-1 {
-				content.WriteString("\n")
-			}
-		}
-	}
-
-	// 文件尾部
-	content.WriteString("\n")
-	content.WriteString("=====================================\n")
-	content.WriteString(fmt.Sprintf("导出时间: %s\n", time.Now().Format("2006年01月02日 15:04:05")))
-	content.WriteString("由 AI 角色扮演系统生成\n")
-	content.WriteString("=====================================\n")
-
-	// 生成文件名
-	filename := fmt.Sprintf("对话记录_%s_%s.txt",
-		conversation.Title,
-		time.Now().Format("20060102_150405"))
-
-	// 清理文件名中的特殊字符
-	filename = strings.ReplaceAll(filename, "/", "_")
-	filename = strings.ReplaceAll(filename, "\\", "_")
-	filename = strings.ReplaceAll(filename, ":", "_")
-	filename = strings.ReplaceAll(filename, "*", "_")
-	filename = strings.ReplaceAll(filename, "?", "_")
-	filename = strings.ReplaceAll(filename, "\"", "_")
-	filename = strings.ReplaceAll(filename, "<", "_")
-	filename = strings.ReplaceAll(filename, ">", "_")
-	filename = strings.ReplaceAll(filename, "|", "_")
-
-	return &types.ExportResponse{
-		Code:     0,
-		Msg:      "导出成功",
-		Data:     content.String(),
-		Format:   "txt",
-		Filename: filename,
-	}
-}
-
 // FromSendMessageRequest 从发送消息请求创建消息模型
 func (c *ChatConverter) FromSendMessageRequest(req *types.SendMessageRequest) *model.Message {
 	message := &model.Message{
@@ -374,7 +215,7 @@ func (c *ChatConverter) FromSendMessageRequest(req *types.SendMessageRequest) *m
 func (c *ChatConverter) CreateAIMessage(conversationID int64, content string, tokenUsed int32, processingTime int32) *model.Message {
 	return &model.Message{
 		ConversationID: conversationID,
-		Type:           "ai",
+		Type:           common.Message_Type_AI,
 		Content:        content,
 		TokenUsed:      tokenUsed,
 		ProcessingTime: processingTime,
@@ -428,6 +269,10 @@ func (c *ChatConverter) FromCreateConversationRequest(req *types.CreateConversat
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	// 创建时指定了标题，首轮回复后不再自动生成
+	if req.Title != "" && req.Title != "新对话" {
+		conversation.TitleCustomized = 1
+	}
 
 	return conversation
 }
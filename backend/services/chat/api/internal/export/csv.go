@@ -0,0 +1,63 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvExporter CSV，每条消息一行，带 UTF-8 BOM 以便 Excel 正确识别中文
+type csvExporter struct{}
+
+func (csvExporter) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (csvExporter) Extension() string { return "csv" }
+
+var csvHeader = []string{"message_id", "parent_id", "type", "speaker", "content", "created_at", "token_used", "processing_time", "audio_id"}
+
+func (csvExporter) Export(w io.Writer, doc *Document) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	messages := doc.Path
+	for i := range messages {
+		message := &messages[i]
+		record := []string{
+			strconv.FormatInt(message.ID, 10),
+			optionalID(message.ParentID),
+			csvCell(message.Type),
+			csvCell(doc.Speaker(message)),
+			csvCell(message.Content),
+			message.CreatedAt.Format(timeLayout),
+			strconv.Itoa(int(message.TokenUsed)),
+			strconv.Itoa(int(message.ProcessingTime)),
+			optionalID(message.AudioID),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell 以公式字符开头的内容前加单引号，避免在 Excel 中被当作公式执行
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
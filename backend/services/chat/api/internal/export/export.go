@@ -0,0 +1,130 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+	"time"
+
+	common "ai-roleplay/common/utils"
+	characterModel "ai-roleplay/services/character/model"
+	"ai-roleplay/services/chat/model"
+)
+
+// 导出格式
+const (
+	FormatText     = "txt"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+	FormatCSV      = "csv"
+)
+
+// Document 一次导出的对话数据
+type Document struct {
+	Conversation *model.Conversation
+	Messages     []model.Message                     // 全部消息，含未选中的分支，按时间正序
+	Path         []model.Message                     // 当前选中的分支路径，即界面上展示的对话
+	Characters   map[int64]*characterModel.Character // 对话及发言涉及的角色
+	AudioFiles   map[int64]*model.AudioFile          // 消息引用的语音文件
+	ExportedAt   time.Time
+}
+
+// Character 对话的主角色，未找到时为 nil
+func (d *Document) Character() *characterModel.Character {
+	return d.Characters[d.Conversation.CharacterID]
+}
+
+// Speaker 消息发送者的显示名称：用户消息为“用户”，AI消息为发言角色的名称
+func (d *Document) Speaker(message *model.Message) string {
+	if message.Type == common.Message_Type_User {
+		return "用户"
+	}
+	characterID := d.Conversation.CharacterID
+	if message.CharacterID != nil {
+		characterID = *message.CharacterID
+	}
+	if character := d.Characters[characterID]; character != nil {
+		return character.Name
+	}
+	return "AI助手"
+}
+
+// Exporter 一种导出格式
+type Exporter interface {
+	ContentType() string
+	Extension() string
+	Export(w io.Writer, doc *Document) error
+}
+
+// Registry 导出格式注册表，格式名称不区分大小写
+type Registry struct {
+	exporters map[string]Exporter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{exporters: make(map[string]Exporter)}
+}
+
+// Register 按格式名称注册导出器
+func (r *Registry) Register(format string, exporter Exporter) {
+	r.exporters[strings.ToLower(format)] = exporter
+}
+
+// Get 按格式名称取导出器
+func (r *Registry) Get(format string) (Exporter, error) {
+	exporter, ok := r.exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的导出格式: %s，可选: %s", format, strings.Join(r.Formats(), ", "))
+	}
+	return exporter, nil
+}
+
+// Formats 返回已注册的格式名称
+func (r *Registry) Formats() []string {
+	formats := make([]string, 0, len(r.exporters))
+	for format := range r.exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewBuiltinRegistry 注册内置导出格式
+func NewBuiltinRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(FormatText, textExporter{})
+	r.Register(FormatMarkdown, markdownExporter{})
+	r.Register("md", markdownExporter{})
+	r.Register(FormatJSON, jsonExporter{})
+	r.Register(FormatHTML, htmlExporter{})
+	r.Register(FormatCSV, csvExporter{})
+	return r
+}
+
+// Filename 下载文件名：对话标题加导出时间，去掉文件名中不允许的字符
+func Filename(doc *Document, exporter Exporter) string {
+	name := fmt.Sprintf("对话记录_%s_%s.%s", doc.Conversation.Title, doc.ExportedAt.Format("20060102_150405"), exporter.Extension())
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// File 导出结果，作为文件下载返回
+type File struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// ContentDisposition 附件下载的响应头，非 ASCII 文件名按 RFC 2231 编码
+func (f *File) ContentDisposition() string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": f.Filename})
+}
+
+const timeLayout = "2006-01-02 15:04:05"
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	characterModel "ai-roleplay/services/character/model"
+	"ai-roleplay/services/chat/model"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// testDocument 用户消息下有两个回复分支，当前选中的是第二个
+func testDocument() *Document {
+	created := time.Date(2026, 5, 1, 10, 0, 0, 0, time.Local)
+	messages := []model.Message{
+		{ID: 1, Type: "user", IsActive: 1, Content: "你好 <b>", AudioID: ptr(int64(9)), CreatedAt: created},
+		{ID: 2, ParentID: ptr(int64(1)), Branch: 1, Type: "ai", Content: "旧回复", CreatedAt: created.Add(time.Second)},
+		{ID: 3, ParentID: ptr(int64(1)), Branch: 2, IsActive: 1, Type: "ai", CharacterID: ptr(int64(3)), Content: "你好，\n欢迎，来到\"学院\"", Metadata: ptr(`{"provider":"mock"}`), TokenUsed: 12, CreatedAt: created.Add(2 * time.Second)},
+	}
+	return &Document{
+		Conversation: &model.Conversation{ID: 7, CharacterID: 3, Title: "魔法/学院", CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+		Messages:     messages,
+		Path:         []model.Message{messages[0], messages[2]},
+		Characters:   map[int64]*characterModel.Character{3: {ID: 3, Name: "艾琳", Avatar: ptr("https://example.com/a.png")}},
+		AudioFiles:   map[int64]*model.AudioFile{9: {ID: 9, Type: "stt", Filename: "9.wav", Format: "wav"}},
+		ExportedAt:   created.Add(2 * time.Hour),
+	}
+}
+
+func export(t *testing.T, format string) string {
+	t.Helper()
+	exporter, err := NewBuiltinRegistry().Get(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, testDocument()); err != nil {
+		t.Fatalf("export %s: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewBuiltinRegistry()
+	if exporter, err := r.Get("MD"); err != nil || exporter.Extension() != "md" {
+		t.Errorf("md alias: %v %v", exporter, err)
+	}
+	if _, err := r.Get("pdf"); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+	exporter, _ := r.Get(FormatHTML)
+	if got := Filename(testDocument(), exporter); got != "对话记录_魔法_学院_20260501_120000.html" {
+		t.Errorf("Filename = %s", got)
+	}
+}
+
+func TestJSONIsLossless(t *testing.T) {
+	var out struct {
+		Version  int `json:"version"`
+		Messages []struct {
+			ID       int64            `json:"id"`
+			IsActive int32            `json:"is_active"`
+			Metadata map[string]any   `json:"metadata"`
+			Audio    *model.AudioFile `json:"audio"`
+		} `json:"messages"`
+		Characters []jsonCharacter `json:"characters"`
+	}
+	if err := json.Unmarshal([]byte(export(t, FormatJSON)), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != jsonVersion || len(out.Messages) != 3 || len(out.Characters) != 1 {
+		t.Fatalf("got %+v", out)
+	}
+	if out.Messages[1].IsActive != 0 {
+		t.Error("inactive branch should be kept with its flag")
+	}
+	if out.Messages[2].Metadata["provider"] != "mock" {
+		t.Errorf("metadata = %v", out.Messages[2].Metadata)
+	}
+	if out.Messages[0].Audio == nil || out.Messages[0].Audio.Filename != "9.wav" {
+		t.Errorf("audio = %+v", out.Messages[0].Audio)
+	}
+}
+
+func TestTextFormatsFollowActivePath(t *testing.T) {
+	for _, format := range []string{FormatText, FormatMarkdown, FormatHTML} {
+		out := export(t, format)
+		if strings.Contains(out, "旧回复") {
+			t.Errorf("%s: inactive branch exported", format)
+		}
+		if !strings.Contains(out, "艾琳") {
+			t.Errorf("%s: speaker name missing", format)
+		}
+	}
+}
+
+func TestHTMLEscapesContent(t *testing.T) {
+	out := export(t, FormatHTML)
+	if strings.Contains(out, "<b>") || !strings.Contains(out, "你好 &lt;b&gt;") {
+		t.Error("message content should be escaped")
+	}
+	if !strings.Contains(out, `src="https://example.com/a.png"`) {
+		t.Error("avatar missing")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	out := export(t, FormatCSV)
+	if !strings.HasPrefix(out, "\ufeff") {
+		t.Error("missing BOM")
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d rows", len(records))
+	}
+	if records[2][0] != "3" || records[2][1] != "1" || records[2][4] != "你好，\n欢迎，来到\"学院\"" || records[1][8] != "9" {
+		t.Errorf("rows = %q", records)
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	for value, want := range map[string]string{
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-1":                "'-1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\tcmd":             "'\tcmd",
+		"\rcmd":             "'\rcmd",
+		"你好":                "你好",
+		"":                  "",
+	} {
+		if got := csvCell(value); got != want {
+			t.Errorf("csvCell(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	file := &File{Filename: "对话记录 1.txt"}
+	if got := file.ContentDisposition(); got != "attachment; filename*=utf-8''%E5%AF%B9%E8%AF%9D%E8%AE%B0%E5%BD%95%201.txt" {
+		t.Errorf("ContentDisposition = %s", got)
+	}
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"html/template"
+	"io"
+
+	common "ai-roleplay/common/utils"
+)
+
+// htmlExporter 单文件 HTML，样式内联，AI消息旁显示角色头像
+type htmlExporter struct{}
+
+func (htmlExporter) ContentType() string { return "text/html; charset=utf-8" }
+
+func (htmlExporter) Extension() string { return "html" }
+
+type htmlPage struct {
+	Title         string
+	CharacterName string
+	Avatar        string
+	CreatedAt     string
+	ExportedAt    string
+	Messages      []htmlMessage
+}
+
+type htmlMessage struct {
+	Speaker string
+	Avatar  string
+	IsUser  bool
+	Time    string
+	Content string
+	AudioID int64
+}
+
+func (htmlExporter) Export(w io.Writer, doc *Document) error {
+	page := htmlPage{
+		Title:      doc.Conversation.Title,
+		CreatedAt:  doc.Conversation.CreatedAt.Format(timeLayout),
+		ExportedAt: doc.ExportedAt.Format(timeLayout),
+	}
+	if character := doc.Character(); character != nil {
+		page.CharacterName = character.Name
+		if character.Avatar != nil {
+			page.Avatar = *character.Avatar
+		}
+	}
+
+	messages := doc.Path
+	for i := range messages {
+		message := &messages[i]
+		item := htmlMessage{
+			Speaker: doc.Speaker(message),
+			IsUser:  message.Type == common.Message_Type_User,
+			Time:    message.CreatedAt.Format(timeLayout),
+			Content: message.Content,
+		}
+		if !item.IsUser {
+			item.Avatar = page.Avatar
+			if message.CharacterID != nil {
+				if character := doc.Characters[*message.CharacterID]; character != nil && character.Avatar != nil {
+					item.Avatar = *character.Avatar
+				}
+			}
+		}
+		if message.AudioID != nil {
+			item.AudioID = *message.AudioID
+		}
+		page.Messages = append(page.Messages, item)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+var htmlTemplate = template.Must(template.New("conversation").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; background: #f5f6f8; color: #222; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; }
+.container { max-width: 800px; margin: 0 auto; padding: 24px 16px; }
+header { display: flex; align-items: center; gap: 16px; margin-bottom: 24px; }
+header img { width: 64px; height: 64px; border-radius: 50%; object-fit: cover; }
+header h1 { margin: 0 0 4px; font-size: 22px; }
+.meta { color: #888; font-size: 13px; }
+.message { display: flex; gap: 12px; margin: 16px 0; }
+.message.user { flex-direction: row-reverse; }
+.avatar { width: 40px; height: 40px; border-radius: 50%; object-fit: cover; flex-shrink: 0; background: #dde; }
+.bubble { max-width: 75%; }
+.message.user .bubble { text-align: right; }
+.speaker { font-size: 12px; color: #888; margin-bottom: 4px; }
+.content { display: inline-block; text-align: left; padding: 10px 14px; border-radius: 10px; background: #fff; white-space: pre-wrap; word-break: break-word; line-height: 1.6; }
+.message.user .content { background: #d7ecff; }
+.audio { font-size: 12px; color: #888; margin-top: 4px; }
+footer { margin-top: 32px; text-align: center; color: #aaa; font-size: 12px; }
+</style>
+</head>
+<body>
+<div class="container">
+<header>
+{{if .Avatar}}<img src="{{.Avatar}}" alt="{{.CharacterName}}">{{end}}
+<div>
+<h1>{{.Title}}</h1>
+<div class="meta">{{if .CharacterName}}角色：{{.CharacterName}} · {{end}}创建于 {{.CreatedAt}} · {{len .Messages}} 条消息</div>
+</div>
+</header>
+{{range .Messages}}<div class="message{{if .IsUser}} user{{end}}">
+{{if .IsUser}}<div class="avatar"></div>{{else if .Avatar}}<img class="avatar" src="{{.Avatar}}" alt="{{.Speaker}}">{{else}}<div class="avatar"></div>{{end}}
+<div class="bubble">
+<div class="speaker">{{.Speaker}} · {{.Time}}</div>
+<div class="content">{{.Content}}</div>
+{{if .AudioID}}<div class="audio">🔊 语音消息（音频ID: {{.AudioID}}）</div>{{end}}
+</div>
+</div>
+{{else}}<p class="meta">暂无对话消息</p>
+{{end}}<footer>导出时间：{{.ExportedAt}} · 由 AI 角色扮演系统生成</footer>
+</div>
+</body>
+</html>
+`))
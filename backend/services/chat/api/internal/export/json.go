@@ -0,0 +1,85 @@
+package export
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+	"time"
+
+	"ai-roleplay/services/chat/model"
+)
+
+// jsonVersion JSON 导出结构的版本，结构不兼容变化时递增
+const jsonVersion = 1
+
+// jsonExporter 结构化 JSON，包含全部分支、元数据和语音文件信息，可无损还原对话
+type jsonExporter struct{}
+
+func (jsonExporter) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonExporter) Extension() string { return "json" }
+
+type jsonDocument struct {
+	Version      int                 `json:"version"`
+	ExportedAt   time.Time           `json:"exported_at"`
+	Conversation *model.Conversation `json:"conversation"`
+	Characters   []jsonCharacter     `json:"characters"`
+	Messages     []jsonMessage       `json:"messages"`
+}
+
+type jsonCharacter struct {
+	ID     int64   `json:"id"`
+	Name   string  `json:"name"`
+	Avatar *string `json:"avatar"`
+}
+
+// jsonMessage 元数据以 JSON 对象输出而不是字符串，并附带引用的语音文件
+type jsonMessage struct {
+	model.Message
+	Metadata json.RawMessage  `json:"metadata"`
+	Audio    *model.AudioFile `json:"audio,omitempty"`
+}
+
+func (jsonExporter) Export(w io.Writer, doc *Document) error {
+	out := jsonDocument{
+		Version:      jsonVersion,
+		ExportedAt:   doc.ExportedAt,
+		Conversation: doc.Conversation,
+		Characters:   make([]jsonCharacter, 0, len(doc.Characters)),
+		Messages:     make([]jsonMessage, 0, len(doc.Messages)),
+	}
+	for _, character := range doc.Characters {
+		out.Characters = append(out.Characters, jsonCharacter{
+			ID:     character.ID,
+			Name:   character.Name,
+			Avatar: character.Avatar,
+		})
+	}
+	slices.SortFunc(out.Characters, func(a, b jsonCharacter) int { return cmp.Compare(a.ID, b.ID) })
+
+	for _, message := range doc.Messages {
+		item := jsonMessage{Message: message, Metadata: rawJSON(message.Metadata)}
+		if message.AudioID != nil {
+			item.Audio = doc.AudioFiles[*message.AudioID]
+		}
+		out.Messages = append(out.Messages, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(out)
+}
+
+// rawJSON 原样输出合法的 JSON，不合法时按字符串输出，为空时输出 null
+func rawJSON(value *string) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	if json.Valid([]byte(*value)) {
+		return json.RawMessage(*value)
+	}
+	data, _ := json.Marshal(*value)
+	return data
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownExporter Markdown，每条消息一个小节，内容按原样保留
+type markdownExporter struct{}
+
+func (markdownExporter) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownExporter) Extension() string { return "md" }
+
+func (markdownExporter) Export(w io.Writer, doc *Document) error {
+	conversation := doc.Conversation
+	messages := doc.Path
+	content := bufio.NewWriter(w)
+
+	fmt.Fprintf(content, "# %s\n\n", markdownEscape(conversation.Title))
+	if character := doc.Character(); character != nil {
+		fmt.Fprintf(content, "- 角色：%s\n", markdownEscape(character.Name))
+	}
+	fmt.Fprintf(content, "- 创建时间：%s\n", conversation.CreatedAt.Format(timeLayout))
+	fmt.Fprintf(content, "- 最后更新：%s\n", conversation.UpdatedAt.Format(timeLayout))
+	fmt.Fprintf(content, "- 消息数：%d\n", len(messages))
+	fmt.Fprintf(content, "- 导出时间：%s\n", doc.ExportedAt.Format(timeLayout))
+
+	for i := range messages {
+		message := &messages[i]
+		fmt.Fprintf(content, "\n---\n\n### %s · %s\n\n", markdownEscape(doc.Speaker(message)), message.CreatedAt.Format(timeLayout))
+		content.WriteString(strings.TrimRight(message.Content, "\n"))
+		content.WriteString("\n")
+		if message.AudioID != nil {
+			fmt.Fprintf(content, "\n> 🔊 语音消息（音频ID: %d）\n", *message.AudioID)
+		}
+	}
+
+	return content.Flush()
+}
+
+// markdownEscape 转义标题和列表中会被解析为格式的字符，消息正文保持原样
+func markdownEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "[", `\[`, "]", `\]`, "\n", " ").Replace(text)
+}
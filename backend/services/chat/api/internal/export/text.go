@@ -0,0 +1,166 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/model"
+)
+
+// textExporter 纯文本，保留原有的导出版式
+type textExporter struct{}
+
+func (textExporter) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textExporter) Extension() string { return "txt" }
+
+func (textExporter) Export(w io.Writer, doc *Document) error {
+	conversation := doc.Conversation
+	messages := doc.Path
+	content := bufio.NewWriter(w)
+
+	// 文件头信息
+	content.WriteString("=====================================\n")
+	content.WriteString("        AI 角色扮演对话记录\n")
+	content.WriteString("=====================================\n\n")
+
+	// 对话基本信息
+	content.WriteString("对话信息:\n")
+	content.WriteString("--------\n")
+	fmt.Fprintf(content, "对话ID: %d\n", conversation.ID)
+	fmt.Fprintf(content, "对话标题: %s\n", conversation.Title)
+	fmt.Fprintf(content, "角色ID: %d\n", conversation.CharacterID)
+	if character := doc.Character(); character != nil {
+		fmt.Fprintf(content, "角色名称: %s\n", character.Name)
+	}
+	if conversation.UserID != nil {
+		fmt.Fprintf(content, "用户ID: %d\n", *conversation.UserID)
+	}
+	fmt.Fprintf(content, "创建时间: %s\n", conversation.CreatedAt.Format("2006年01月02日 15:04:05"))
+	fmt.Fprintf(content, "最后更新: %s\n", conversation.UpdatedAt.Format("2006年01月02日 15:04:05"))
+	fmt.Fprintf(content, "消息总数: %d条\n", len(messages))
+
+	// 计算对话时长
+	duration := conversation.UpdatedAt.Sub(conversation.CreatedAt)
+	if duration.Hours() >= 24 {
+		fmt.Fprintf(content, "对话时长: %.1f天\n", duration.Hours()/24)
+	} else if duration.Hours() >= 1 {
+		fmt.Fprintf(content, "对话时长: %.1f小时\n", duration.Hours())
+	} else {
+		fmt.Fprintf(content, "对话时长: %.0f分钟\n", duration.Minutes())
+	}
+
+	// 统计信息
+	stats := statsOf(messages)
+	fmt.Fprintf(content, "用户消息: %d条\n", stats.userMessages)
+	fmt.Fprintf(content, "AI消息: %d条\n", stats.aiMessages)
+	fmt.Fprintf(content, "总Token消耗: %d\n", stats.totalTokens)
+	fmt.Fprintf(content, "总处理时间: %.2f秒\n", float64(stats.totalProcessingTime)/1000)
+	if stats.aiMessages > 0 {
+		fmt.Fprintf(content, "平均Token/消息: %.1f\n", float64(stats.totalTokens)/float64(stats.aiMessages))
+		fmt.Fprintf(content, "平均处理时间: %.0f毫秒\n", float64(stats.totalProcessingTime)/float64(stats.aiMessages))
+	}
+	content.WriteString("\n")
+
+	// 对话内容
+	content.WriteString("对话内容:\n")
+	content.WriteString("--------\n\n")
+
+	if len(messages) == 0 {
+		content.WriteString("暂无对话消息\n")
+	}
+	for i := range messages {
+		message := &messages[i]
+		senderIcon := "🤖"
+		if message.Type == common.Message_Type_User {
+			senderIcon = "👤"
+		}
+		fmt.Fprintf(content, "[%d] %s %s (%s)", i+1, senderIcon, doc.Speaker(message), message.CreatedAt.Format("15:04:05"))
+
+		// AI消息的额外信息
+		if message.Type != common.Message_Type_User && (message.TokenUsed > 0 || message.ProcessingTime > 0) {
+			var extra []string
+			if message.TokenUsed > 0 {
+				extra = append(extra, fmt.Sprintf("Token: %d", message.TokenUsed))
+			}
+			if message.ProcessingTime > 0 {
+				extra = append(extra, fmt.Sprintf("耗时: %dms", message.ProcessingTime))
+			}
+			fmt.Fprintf(content, " [%s]", strings.Join(extra, ", "))
+		}
+		content.WriteString("\n")
+
+		// 消息内容（处理多行文本）
+		for _, line := range strings.Split(message.Content, "\n") {
+			fmt.Fprintf(content, "    %s\n", line)
+		}
+
+		if message.AudioID != nil {
+			fmt.Fprintf(content, "    🔊 语音消息 (音频ID: %d)\n", *message.AudioID)
+		}
+		if metadata := metadataPairs(message); metadata != "" {
+			fmt.Fprintf(content, "    📋 元数据: %s\n", metadata)
+		}
+
+		// 消息间分隔
+		if i < len(messages)-1 {
+			content.WriteString("\n")
+		}
+	}
+
+	// 文件尾部
+	content.WriteString("\n")
+	content.WriteString("=====================================\n")
+	fmt.Fprintf(content, "导出时间: %s\n", doc.ExportedAt.Format("2006年01月02日 15:04:05"))
+	content.WriteString("由 AI 角色扮演系统生成\n")
+	content.WriteString("=====================================\n")
+
+	return content.Flush()
+}
+
+type messageStats struct {
+	userMessages        int
+	aiMessages          int
+	totalTokens         int32
+	totalProcessingTime int32
+}
+
+func statsOf(messages []model.Message) messageStats {
+	var stats messageStats
+	for _, message := range messages {
+		if message.Type == common.Message_Type_User {
+			stats.userMessages++
+			continue
+		}
+		stats.aiMessages++
+		stats.totalTokens += message.TokenUsed
+		stats.totalProcessingTime += message.ProcessingTime
+	}
+	return stats
+}
+
+// metadataPairs 把消息元数据格式化为按键排序的 key=value 列表，无元数据时为空
+func metadataPairs(message *model.Message) string {
+	if message.Metadata == nil {
+		return ""
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(*message.Metadata), &metadata); err != nil || len(metadata) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, metadata[key]))
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,85 @@
+package feedback
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// 评价
+const (
+	VoteUp   = "up"
+	VoteDown = "down"
+)
+
+// MaxCommentLength 补充说明的最大字符数
+const MaxCommentLength = 500
+
+// 原因标签，赞和踩各有一组，导出时用于按问题类型调整提示词
+var (
+	UpReasons = []string{
+		"helpful",      // 有帮助
+		"in_character", // 符合角色设定
+		"creative",     // 有创意
+		"accurate",     // 内容准确
+	}
+	DownReasons = []string{
+		"out_of_character", // 不符合角色设定
+		"inaccurate",       // 内容错误
+		"unhelpful",        // 没有帮助
+		"repetitive",       // 重复啰嗦
+		"too_long",         // 太长
+		"too_short",        // 太短
+		"unsafe",           // 不当内容
+	}
+)
+
+// VoteValue 评价对应的存储值：赞为1，踩为-1
+func VoteValue(vote string) (int8, bool) {
+	switch vote {
+	case VoteUp:
+		return 1, true
+	case VoteDown:
+		return -1, true
+	}
+	return 0, false
+}
+
+// VoteName 存储值对应的评价
+func VoteName(value int8) string {
+	if value > 0 {
+		return VoteUp
+	}
+	return VoteDown
+}
+
+// Normalize 校验评价、原因标签和补充说明，去掉重复的标签
+func Normalize(vote string, reasons []string, comment string) (int8, []string, error) {
+	value, ok := VoteValue(vote)
+	if !ok {
+		return 0, nil, fmt.Errorf("评价无效: %s", vote)
+	}
+	allowed := UpReasons
+	if vote == VoteDown {
+		allowed = DownReasons
+	}
+
+	normalized := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		if !slices.Contains(allowed, reason) {
+			return 0, nil, fmt.Errorf("不支持的原因: %s", reason)
+		}
+		if !slices.Contains(normalized, reason) {
+			normalized = append(normalized, reason)
+		}
+	}
+	if utf8.RuneCountInString(comment) > MaxCommentLength {
+		return 0, nil, fmt.Errorf("补充说明不能超过%d个字", MaxCommentLength)
+	}
+	return value, normalized, nil
+}
+
+// ValidScore 星级评分是否在1-5之间
+func ValidScore(score int) bool {
+	return score >= 1 && score <= 5
+}
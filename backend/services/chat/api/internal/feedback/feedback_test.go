@@ -0,0 +1,38 @@
+package feedback
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	value, reasons, err := Normalize(VoteDown, []string{"too_long", "unsafe", "too_long"}, "太啰嗦了")
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if value != -1 || len(reasons) != 2 || reasons[0] != "too_long" || reasons[1] != "unsafe" {
+		t.Errorf("got %d %v", value, reasons)
+	}
+
+	if _, _, err := Normalize(VoteUp, []string{"too_long"}, ""); err == nil {
+		t.Error("down reason should be rejected for up vote")
+	}
+	if _, _, err := Normalize("meh", nil, ""); err == nil {
+		t.Error("invalid vote should be rejected")
+	}
+	if _, _, err := Normalize(VoteUp, nil, strings.Repeat("好", MaxCommentLength+1)); err == nil {
+		t.Error("long comment should be rejected")
+	}
+	if value, reasons, err := Normalize(VoteUp, nil, ""); err != nil || value != 1 || len(reasons) != 0 {
+		t.Errorf("plain up vote: %d %v %v", value, reasons, err)
+	}
+}
+
+func TestVoteName(t *testing.T) {
+	for _, vote := range []string{VoteUp, VoteDown} {
+		value, _ := VoteValue(vote)
+		if got := VoteName(value); got != vote {
+			t.Errorf("VoteName(%d) = %s, want %s", value, got, vote)
+		}
+	}
+}
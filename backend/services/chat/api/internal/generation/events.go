@@ -0,0 +1,102 @@
+package generation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// eventsKey 每次生成的事件缓冲，使用 0-<seq> 作为 Redis Stream 条目ID，便于按序号续读
+const eventsKey = "chat:generation:events:%s"
+
+// Event 缓冲中的一条事件
+type Event struct {
+	Seq   int64
+	Data  []byte
+	Final bool // 是否为结束事件（完成或出错）
+}
+
+// EventID 组成 SSE 的 id 字段，格式为 <生成ID>:<序号>
+func EventID(generationID string, seq int64) string {
+	return fmt.Sprintf("%s:%d", generationID, seq)
+}
+
+// ParseEventID 解析客户端重连时携带的 Last-Event-ID
+func ParseEventID(id string) (string, int64, bool) {
+	generationID, seqText, found := strings.Cut(strings.TrimSpace(id), ":")
+	if !found || generationID == "" {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseInt(seqText, 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return generationID, seq, true
+}
+
+// NextSeq 分配下一个事件序号，只能由执行生成的协程调用
+func (g *Generation) NextSeq() int64 {
+	g.seq++
+	return g.seq
+}
+
+// Append 把事件写入生成的缓冲，并刷新缓冲的过期时间
+func (m *Manager) Append(ctx context.Context, generationID string, seq int64, data []byte, final bool) error {
+	key := fmt.Sprintf(eventsKey, generationID)
+	pipe := m.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		ID:     fmt.Sprintf("0-%d", seq),
+		Values: map[string]interface{}{
+			"data":  data,
+			"final": strconv.FormatBool(final),
+		},
+	})
+	pipe.Expire(ctx, key, m.eventTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Replay 读取 afterSeq 之后的事件，暂时没有新事件时最多阻塞 block
+func (m *Manager) Replay(ctx context.Context, generationID string, afterSeq int64, block time.Duration) ([]Event, error) {
+	streams, err := m.redis.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{fmt.Sprintf(eventsKey, generationID), fmt.Sprintf("0-%d", afterSeq)},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			_, seqText, _ := strings.Cut(message.ID, "-")
+			seq, err := strconv.ParseInt(seqText, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid event id %q", message.ID)
+			}
+			data, _ := message.Values["data"].(string)
+			final, _ := message.Values["final"].(string)
+			events = append(events, Event{
+				Seq:   seq,
+				Data:  []byte(data),
+				Final: final == "true",
+			})
+		}
+	}
+	return events, nil
+}
+
+// Active 生成任务是否仍在进行
+func (m *Manager) Active(ctx context.Context, generationID string) (bool, error) {
+	n, err := m.redis.Exists(ctx, fmt.Sprintf(generationKey, generationID)).Result()
+	return n > 0, err
+}
@@ -0,0 +1,151 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	generationKey = "chat:generation:%s"        // 生成任务的登记信息
+	cancelChannel = "chat:generation:cancel:%s" // 每个实例订阅自己的取消频道
+)
+
+var (
+	// ErrStopped 用户主动停止生成时作为 context 的取消原因
+	ErrStopped = errors.New("generation stopped by user")
+	// ErrNotFound 生成任务不存在、已结束或不属于当前用户
+	ErrNotFound = errors.New("生成任务不存在或已结束")
+)
+
+// Generation 一次进行中的流式生成
+type Generation struct {
+	ID             string
+	ConversationID int64
+	UserID         int64
+
+	seq int64
+}
+
+// Manager 在 Redis 中登记本实例上进行中的生成任务，并通过 pub/sub 接收其他实例转发的取消请求
+type Manager struct {
+	redis    *redis.Client
+	instance string
+	ttl      time.Duration
+	eventTTL time.Duration
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func NewManager(rdb *redis.Client, ttl, eventTTL time.Duration) *Manager {
+	hostname, _ := os.Hostname()
+	return &Manager{
+		redis:    rdb,
+		instance: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		ttl:      ttl,
+		eventTTL: eventTTL,
+		cancels:  make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Start 订阅本实例的取消频道，直到 ctx 结束
+func (m *Manager) Start(ctx context.Context) {
+	sub := m.redis.Subscribe(ctx, fmt.Sprintf(cancelChannel, m.instance))
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Channel():
+			if !ok {
+				return
+			}
+			m.cancelLocal(msg.Payload)
+		}
+	}
+}
+
+// Register 登记一次生成，返回的 ctx 会在用户停止或超过最长生成时间时取消，结束后必须调用 Finish
+func (m *Manager) Register(parent context.Context, conversationID, userID int64) (*Generation, context.Context, error) {
+	generation := &Generation{
+		ID:             uuid.NewString(),
+		ConversationID: conversationID,
+		UserID:         userID,
+	}
+
+	key := fmt.Sprintf(generationKey, generation.ID)
+	if err := m.redis.HSet(parent, key, map[string]interface{}{
+		"instance":        m.instance,
+		"conversation_id": conversationID,
+		"user_id":         userID,
+		"started_at":      time.Now().Unix(),
+	}).Err(); err != nil {
+		return nil, nil, err
+	}
+	m.redis.Expire(parent, key, m.ttl+time.Minute)
+
+	ctx, cancelCause := context.WithCancelCause(parent)
+	ctx, stop := context.WithTimeout(ctx, m.ttl)
+	cancel := func(cause error) {
+		cancelCause(cause)
+		stop()
+	}
+
+	m.mu.Lock()
+	m.cancels[generation.ID] = cancel
+	m.mu.Unlock()
+	return generation, ctx, nil
+}
+
+// Finish 注销生成任务并释放其 context
+func (m *Manager) Finish(generation *Generation) {
+	m.mu.Lock()
+	cancel, ok := m.cancels[generation.ID]
+	delete(m.cancels, generation.ID)
+	m.mu.Unlock()
+	if ok {
+		cancel(context.Canceled)
+	}
+
+	if err := m.redis.Del(context.Background(), fmt.Sprintf(generationKey, generation.ID)).Err(); err != nil {
+		logx.Errorf("Finish generation %s failed: %v", generation.ID, err)
+	}
+}
+
+// Cancel 停止指定的生成任务，任务可以在任意实例上
+func (m *Manager) Cancel(ctx context.Context, generationID string, userID int64) error {
+	values, err := m.redis.HGetAll(ctx, fmt.Sprintf(generationKey, generationID)).Result()
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 || values["user_id"] != strconv.FormatInt(userID, 10) {
+		return ErrNotFound
+	}
+
+	instance := values["instance"]
+	if instance == m.instance {
+		m.cancelLocal(generationID)
+		return nil
+	}
+	return m.redis.Publish(ctx, fmt.Sprintf(cancelChannel, instance), generationID).Err()
+}
+
+func (m *Manager) cancelLocal(generationID string) {
+	m.mu.Lock()
+	cancel, ok := m.cancels[generationID]
+	m.mu.Unlock()
+	if ok {
+		logx.Infof("Generation %s stopped by user", generationID)
+		cancel(ErrStopped)
+	}
+}
@@ -0,0 +1,80 @@
+package group
+
+import (
+	"strings"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/model"
+)
+
+// 群聊发言策略
+const (
+	PolicyRoundRobin = "round_robin" // 参与角色按顺序轮流回复
+	PolicyMention    = "mention"     // 回复用户@到的角色，没有点名时轮流
+	PolicyAuto       = "auto"        // 由模型根据对话内容选择，失败时轮流
+)
+
+// ValidPolicy 是否为支持的发言策略
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case PolicyRoundRobin, PolicyMention, PolicyAuto:
+		return true
+	}
+	return false
+}
+
+// Member 群聊中的一个角色，按轮流顺序排列
+type Member struct {
+	CharacterID int64
+	Name        string
+}
+
+// Find 按角色ID查找参与角色
+func Find(members []Member, characterID int64) (Member, bool) {
+	for _, member := range members {
+		if member.CharacterID == characterID {
+			return member, true
+		}
+	}
+	return Member{}, false
+}
+
+// LastSpeaker 返回对话路径上最近一条AI回复的发言角色，没有时返回0
+func LastSpeaker(path []model.Message) int64 {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Type != common.AI_Role_User && path[i].CharacterID != nil {
+			return *path[i].CharacterID
+		}
+	}
+	return 0
+}
+
+// NextInRotation 返回上一位发言角色的下一位，上一位不在群聊中时从第一位开始
+func NextInRotation(members []Member, lastSpeaker int64) Member {
+	for i, member := range members {
+		if member.CharacterID == lastSpeaker {
+			return members[(i+1)%len(members)]
+		}
+	}
+	return members[0]
+}
+
+// Mentioned 返回消息中最先被@到的角色；同一位置能匹配多个名字时取最长的，避免“@张三丰”被识别为“张三”
+func Mentioned(members []Member, content string) (Member, bool) {
+	best, bestAt := Member{}, -1
+	for _, member := range members {
+		if member.Name == "" {
+			continue
+		}
+		for _, at := range []string{"@", "＠"} {
+			i := strings.Index(content, at+member.Name)
+			if i < 0 {
+				continue
+			}
+			if bestAt < 0 || i < bestAt || (i == bestAt && len(member.Name) > len(best.Name)) {
+				best, bestAt = member, i
+			}
+		}
+	}
+	return best, bestAt >= 0
+}
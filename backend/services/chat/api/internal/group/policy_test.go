@@ -0,0 +1,69 @@
+package group
+
+import (
+	"testing"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/model"
+)
+
+var members = []Member{
+	{CharacterID: 1, Name: "苏格拉底"},
+	{CharacterID: 2, Name: "爱因斯坦"},
+	{CharacterID: 3, Name: "张三"},
+	{CharacterID: 4, Name: "张三丰"},
+}
+
+func TestNextInRotation(t *testing.T) {
+	cases := []struct {
+		last int64
+		want int64
+	}{
+		{0, 1},
+		{1, 2},
+		{4, 1},
+		{99, 1},
+	}
+	for _, c := range cases {
+		if got := NextInRotation(members, c.last); got.CharacterID != c.want {
+			t.Errorf("NextInRotation(%d) = %d, want %d", c.last, got.CharacterID, c.want)
+		}
+	}
+}
+
+func TestMentioned(t *testing.T) {
+	cases := []struct {
+		content string
+		want    int64
+		ok      bool
+	}{
+		{"你们怎么看？", 0, false},
+		{"@爱因斯坦 你怎么看？", 2, true},
+		{"苏格拉底说得对吗 ＠爱因斯坦", 2, true},
+		{"@爱因斯坦 和 @苏格拉底", 2, true},
+		{"@张三丰 请指教", 4, true},
+		{"@张三 你好", 3, true},
+	}
+	for _, c := range cases {
+		got, ok := Mentioned(members, c.content)
+		if ok != c.ok || got.CharacterID != c.want {
+			t.Errorf("Mentioned(%q) = %d, %v, want %d, %v", c.content, got.CharacterID, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestLastSpeaker(t *testing.T) {
+	one, two := int64(1), int64(2)
+	path := []model.Message{
+		{Type: common.AI_Role_User},
+		{Type: common.AI_Role_Assistant, CharacterID: &one},
+		{Type: common.AI_Role_Assistant, CharacterID: &two},
+		{Type: common.AI_Role_User},
+	}
+	if got := LastSpeaker(path); got != 2 {
+		t.Errorf("LastSpeaker = %d, want 2", got)
+	}
+	if got := LastSpeaker(path[:1]); got != 0 {
+		t.Errorf("LastSpeaker without reply = %d, want 0", got)
+	}
+}
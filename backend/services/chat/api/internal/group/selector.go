@@ -0,0 +1,127 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// transcriptSize 模型选择发言角色时参考的最近消息数
+const transcriptSize = 10
+
+// Selector 按群聊的发言策略选出每轮回复的角色
+type Selector struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSelector(ctx context.Context, svcCtx *svc.ServiceContext) *Selector {
+	return &Selector{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Members 读取群聊的参与角色，已下架的角色不再发言
+func (s *Selector) Members(conversationID int64) ([]Member, error) {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	participants, err := chatRepo.GetParticipants(conversationID)
+	if err != nil || len(participants) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(participants))
+	for _, participant := range participants {
+		ids = append(ids, participant.CharacterID)
+	}
+	characters, err := chatRepo.GetCharactersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(characters))
+	for _, character := range characters {
+		names[character.ID] = character.Name
+	}
+
+	members := make([]Member, 0, len(participants))
+	for _, participant := range participants {
+		if name, ok := names[participant.CharacterID]; ok {
+			members = append(members, Member{CharacterID: participant.CharacterID, Name: name})
+		}
+	}
+	return members, nil
+}
+
+// Choose 选出本轮回复的角色，path 为当前分支上的消息（含本轮用户消息）；
+// 点名和 auto 策略无法确定时改为轮流
+func (s *Selector) Choose(policy string, members []Member, path []model.Message) Member {
+	var question string
+	if len(path) > 0 {
+		question = path[len(path)-1].Content
+	}
+
+	if policy == PolicyMention || policy == PolicyAuto {
+		if member, ok := Mentioned(members, question); ok {
+			return member
+		}
+	}
+	if policy == PolicyAuto {
+		member, err := s.ask(members, path)
+		if err == nil {
+			return member
+		}
+		s.Errorf("Choose speaker failed, falling back to round robin: %v", err)
+	}
+	return NextInRotation(members, LastSpeaker(path))
+}
+
+// ask 请模型根据最近的对话选择下一位发言角色
+func (s *Selector) ask(members []Member, path []model.Message) (Member, error) {
+	provider, err := s.svcCtx.LLM.Get(s.svcCtx.Config.Group.Provider)
+	if err != nil {
+		return Member{}, err
+	}
+
+	names := make([]string, 0, len(members))
+	speakers := make(map[int64]string, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+		speakers[member.CharacterID] = member.Name
+	}
+	if len(path) > transcriptSize {
+		path = path[len(path)-transcriptSize:]
+	}
+	lines := make([]string, 0, len(path))
+	for _, message := range path {
+		name := "用户"
+		if message.Type != common.AI_Role_User {
+			name = "角色"
+			if message.CharacterID != nil && speakers[*message.CharacterID] != "" {
+				name = speakers[*message.CharacterID]
+			}
+		}
+		lines = append(lines, prompt.SpeakerContent(name, message.Content))
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.svcCtx.Config.Group.Timeout)
+	defer cancel()
+	result, err := prompt.Generate(ctx, provider.ChatModel, prompt.BuildSpeakerMessages(names, strings.Join(lines, "\n")))
+	if err != nil {
+		return Member{}, err
+	}
+	i, ok := prompt.ParseSpeaker(result.Content, names)
+	if !ok {
+		return Member{}, fmt.Errorf("模型没有选出名单中的角色: %s", result.Content)
+	}
+	return members[i], nil
+}
@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 停止正在进行的生成
+func CancelGenerationHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GenerationRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+			return
+		}
+
+		l := chat.NewCancelGenerationLogic(r.Context(), svcCtx)
+		resp, err := l.CancelGeneration(&req)
+		if err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		} else {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, resp)
+		}
+	}
+}
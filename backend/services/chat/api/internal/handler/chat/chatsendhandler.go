@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/generation"
 	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/ratelimit"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
 
@@ -16,67 +20,134 @@ import (
 	xhttp "github.com/zeromicro/x/http"
 )
 
+const (
+	// resumePollInterval 续传时等待新事件的最长时间，超时后确认生成是否仍在进行
+	resumePollInterval = 5 * time.Second
+	// eventBufferSize 生成与写出之间的事件缓冲，写满后生成等待写出
+	eventBufferSize = 16
+)
+
 // 发送消息并获取SSE流式响应
 func ChatSendHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.ChatSendRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
 			return
 		}
 
-		l := chat.NewChatSendLogic(r.Context(), svcCtx)
-		err := l.ChatSend(&req)
-		if err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
-		} else {
-			httpx.Ok(w)
+		serveSSE(w, r, svcCtx, func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+			return chat.NewChatSendLogic(ctx, svcCtx).Sse(&req, client)
+		})
+	}
+}
+
+// serveSSE 在后台执行 run，并把它写入通道的事件转发给客户端，直到 run 结束或连接断开。
+// 通道由 run 所在的协程关闭，读取方一直读到通道关闭为止：连接断开后改为丢弃事件，
+// run 不会因无人读取而阻塞。run 使用与请求解耦的 context，连接断开后生成继续进行，事件保存在缓冲中；
+// 请求带有 Last-Event-ID 时视为断线重连，从缓冲续传而不再执行 run
+func serveSSE(w http.ResponseWriter, r *http.Request, svcCtx *svc.ServiceContext,
+	run func(ctx context.Context, client chan<- *types.ChatSSEEvent) error) {
+	setSSEHeaders(w)
+
+	if generationID, seq, ok := generation.ParseEventID(r.Header.Get("Last-Event-ID")); ok {
+		resumeSSE(w, r, svcCtx, generationID, seq)
+		return
+	}
+
+	client := make(chan *types.ChatSSEEvent, eventBufferSize)
+	runCtx := ratelimit.WithClientIP(context.WithoutCancel(r.Context()), ratelimit.RequestIP(r, svcCtx.TrustedProxies))
+	threading.GoSafeCtx(runCtx, func() {
+		// 由写入方关闭通道，避免连接断开后继续写入已关闭的通道
+		defer close(client)
+		if err := run(runCtx, client); err != nil {
+			logc.Errorf(runCtx, "sseHandler: %v", err)
+		}
+	})
+	// 连接断开或写出失败后继续取走剩余事件，客户端可凭 Last-Event-ID 从缓冲续传
+	defer threading.GoSafe(func() {
+		for range client {
+		}
+	})
+	for {
+		select {
+		case data, ok := <-client:
+			if !ok { // 通道已关闭
+				return
+			}
+			if err := writeSSE(w, data); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
 		}
 	}
 }
 
-func ChatSendHandler2(svcCtx *svc.ServiceContext) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var req types.ChatSendRequest
-		if err := httpx.Parse(r, &req); err != nil {
-			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+// resumeSSE 回放缓冲中 afterSeq 之后的事件，然后继续跟随，直到生成结束或连接断开
+func resumeSSE(w http.ResponseWriter, r *http.Request, svcCtx *svc.ServiceContext, generationID string, afterSeq int64) {
+	ctx := r.Context()
+	for {
+		events, err := svcCtx.Generations.Replay(ctx, generationID, afterSeq, resumePollInterval)
+		if err != nil {
+			if ctx.Err() == nil {
+				logc.Errorf(ctx, "resumeSSE: replay generation %s failed: %v", generationID, err)
+				writeSSE(w, &types.ChatSSEEvent{Type: common.AI_SSE_Event_Error, Error: "读取生成事件失败"})
+			}
 			return
 		}
 
-		client := make(chan *types.ChatSSEEvent, 16)
-		defer func() {
-			close(client)
-		}()
-		l := chat.NewChatSendLogic(r.Context(), svcCtx)
-		ctx, cancel := context.WithCancel(r.Context())
-		defer cancel() // 确保后台任务取消
-		threading.GoSafeCtx(ctx, func() {
-			err := l.Sse(&req, client)
-			if err != nil {
-				logc.Errorf(r.Context(), "sseHandler", logc.Field("error", err))
+		if len(events) == 0 {
+			// 没有新事件时确认生成是否仍在进行，已结束说明缓冲过期或生成异常退出
+			active, err := svcCtx.Generations.Active(ctx, generationID)
+			if err != nil || !active {
+				writeSSE(w, &types.ChatSSEEvent{
+					Type:         common.AI_SSE_Event_Error,
+					GenerationID: generationID,
+					Error:        "生成记录不存在或已过期",
+				})
 				return
 			}
-		})
-		for {
-			select {
-			case data, ok := <-client:
-				if !ok { // 通道已关闭
-					return
-				}
-				if err := writeSSE(w, data); err != nil {
-					return
-				}
-			case <-ctx.Done():
+			continue
+		}
+
+		for _, event := range events {
+			if err := writeSSEData(w, generation.EventID(generationID, event.Seq), event.Data); err != nil {
+				return
+			}
+			afterSeq = event.Seq
+			if event.Final {
 				return
 			}
 		}
 	}
 }
+
+func setSSEHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+}
+
 func writeSSE(w http.ResponseWriter, event *types.ChatSSEEvent) error {
 	data, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
+	var id string
+	if event.GenerationID != "" && event.Seq > 0 {
+		id = generation.EventID(event.GenerationID, event.Seq)
+	}
+	return writeSSEData(w, id, data)
+}
+
+// writeSSEData 写入一帧SSE数据，id 非空时附带事件ID供客户端断线重连
+func writeSSEData(w http.ResponseWriter, id string, data []byte) error {
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
 	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
 		return err // 写入失败（连接已关闭）
 	}
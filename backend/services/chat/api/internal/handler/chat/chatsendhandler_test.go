@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+)
+
+const streamChunks = 5000
+
+// slowWriter 每写出一定数量的事件暂停一次，模拟网络慢的客户端；写满 limit 个事件后模拟连接断开
+type slowWriter struct {
+	header http.Header
+	cancel context.CancelFunc
+	limit  int
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	frames int
+}
+
+func (w *slowWriter) Header() http.Header { return w.header }
+
+func (w *slowWriter) WriteHeader(int) {}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if bytes.HasPrefix(p, []byte("data: ")) {
+		w.frames++
+		if w.frames%100 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if w.limit > 0 && w.frames == w.limit {
+			w.cancel()
+		}
+	}
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) Flush() {}
+
+func (w *slowWriter) events(t *testing.T) []*types.ChatSSEEvent {
+	t.Helper()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []*types.ChatSSEEvent
+	for _, line := range strings.Split(w.buf.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var event types.ChatSSEEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("Unmarshal event failed: %v", err)
+		}
+		events = append(events, &event)
+	}
+	return events
+}
+
+// streamRun 模拟一轮生成：逐个写入增量事件，写入方式与 ChatSendLogic.sendEvent 相同，通道满时阻塞
+func streamRun(done chan<- int) func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+	return func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+		sent := 0
+		defer func() { done <- sent }()
+		for i := 0; i < streamChunks; i++ {
+			select {
+			case client <- &types.ChatSSEEvent{Type: "message", Content: "x", Seq: int64(i + 1)}:
+				sent++
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+}
+
+func TestServeSSESlowWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &slowWriter{header: http.Header{}, cancel: cancel}
+	r := httptest.NewRequest(http.MethodGet, "/api/chat/send", nil).WithContext(ctx)
+
+	done := make(chan int, 1)
+	serveSSE(w, r, &svc.ServiceContext{}, streamRun(done))
+	if sent := <-done; sent != streamChunks {
+		t.Fatalf("sent %d events, want %d", sent, streamChunks)
+	}
+
+	events := w.events(t)
+	if len(events) != streamChunks {
+		t.Fatalf("received %d events, want %d", len(events), streamChunks)
+	}
+	for i, event := range events {
+		if event.Seq != int64(i+1) {
+			t.Fatalf("event %d has seq %d, events out of order or dropped", i, event.Seq)
+		}
+	}
+}
+
+func TestServeSSEClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &slowWriter{header: http.Header{}, cancel: cancel, limit: 100}
+	r := httptest.NewRequest(http.MethodGet, "/api/chat/send", nil).WithContext(ctx)
+
+	// 客户端断开后生成应继续完成，不能因无人读取而阻塞
+	done := make(chan int, 1)
+	serveSSE(w, r, &svc.ServiceContext{}, streamRun(done))
+	select {
+	case sent := <-done:
+		if sent != streamChunks {
+			t.Fatalf("sent %d events after disconnect, want %d", sent, streamChunks)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer blocked after client disconnected")
+	}
+
+	if got := len(w.events(t)); got < 100 || got >= streamChunks {
+		t.Errorf("received %d events, expected the stream to stop shortly after disconnect", got)
+	}
+}
@@ -0,0 +1,237 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/ratelimit"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/gorilla/websocket"
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+// 客户端消息类型
+const (
+	wsTypeSend       = "send"
+	wsTypeCancel     = "cancel"
+	wsTypeRegenerate = "regenerate"
+	wsTypePing       = "ping"
+)
+
+const (
+	wsMaxMessageSize = 64 << 10         // 单条客户端消息的最大字节数
+	wsPingInterval   = 30 * time.Second // 服务端发送心跳的间隔
+	wsPongWait       = 60 * time.Second // 超过该时间未收到任何数据视为连接失效
+	wsWriteWait      = 10 * time.Second
+)
+
+// WebSocket 聊天通道：一个连接上收发多个对话的消息，支持发送、停止、重新生成和心跳
+func ChatWsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		// WebSocket 不受 CORS 限制，需自行校验来源，防止其他站点借用户的登录态建立连接
+		CheckOrigin: wsCheckOrigin(svcCtx.Config.WebSocket.AllowedOrigins),
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade 失败时已写入HTTP错误响应
+			logc.Errorf(r.Context(), "ChatWsHandler: upgrade failed: %v", err)
+			return
+		}
+
+		session := &wsSession{
+			ctx:    ratelimit.WithClientIP(context.WithoutCancel(r.Context()), ratelimit.RequestIP(r, svcCtx.TrustedProxies)),
+			svcCtx: svcCtx,
+			conn:   conn,
+			out:    make(chan *types.ChatSSEEvent, 64),
+			closed: make(chan struct{}),
+		}
+		session.serve()
+	}
+}
+
+// wsCheckOrigin 允许没有 Origin 的非浏览器客户端、同源页面和配置中的来源
+func wsCheckOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, item := range allowed {
+			if item == "*" || strings.EqualFold(strings.TrimSuffix(item, "/"), u.Scheme+"://"+u.Host) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// wsSession 一个 WebSocket 连接：读循环分发客户端消息，写协程串行推送事件。
+// 生成使用与连接解耦的 context，连接断开后已开始的生成继续进行，可通过续传接口取回
+type wsSession struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	conn   *websocket.Conn
+	out    chan *types.ChatSSEEvent
+	closed chan struct{}
+}
+
+func (s *wsSession) serve() {
+	defer s.conn.Close()
+	defer close(s.closed)
+	threading.GoSafe(s.writeLoop)
+
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logc.Errorf(s.ctx, "ChatWsHandler: read failed: %v", err)
+			}
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		var msg types.ChatWsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.sendError("", "消息格式错误")
+			continue
+		}
+		s.dispatch(&msg)
+	}
+}
+
+// dispatch 按消息类型处理客户端请求，生成类请求在后台执行，不阻塞读循环
+func (s *wsSession) dispatch(msg *types.ChatWsMessage) {
+	switch msg.Type {
+	case wsTypePing:
+		s.send(&types.ChatSSEEvent{
+			Type:      common.AI_SSE_Event_Pong,
+			RequestID: msg.RequestID,
+		})
+	case wsTypeSend:
+		if strings.TrimSpace(msg.Content) == "" {
+			s.sendError(msg.RequestID, "消息内容不能为空")
+			return
+		}
+		req := &types.ChatSendRequest{
+			CharacterID:    msg.CharacterID,
+			ConversationId: msg.ConversationID,
+			Content:        msg.Content,
+			Model:          msg.Model,
+		}
+		s.run(msg.RequestID, func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+			return chat.NewChatSendLogic(ctx, s.svcCtx).Sse(req, client)
+		})
+	case wsTypeRegenerate:
+		if msg.MessageID <= 0 {
+			s.sendError(msg.RequestID, "消息ID无效")
+			return
+		}
+		req := &types.RegenerateRequest{
+			ID:    msg.MessageID,
+			Model: msg.Model,
+		}
+		s.run(msg.RequestID, func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+			return chat.NewRegenerateMessageLogic(ctx, s.svcCtx).Sse(req, client)
+		})
+	case wsTypeCancel:
+		// 停止成功后，该生成自身的事件流会以 done 事件结束
+		requestID, generationID := msg.RequestID, msg.GenerationID
+		threading.GoSafe(func() {
+			l := chat.NewCancelGenerationLogic(s.ctx, s.svcCtx)
+			if _, err := l.CancelGeneration(&types.GenerationRequest{ID: generationID}); err != nil {
+				s.send(&types.ChatSSEEvent{
+					Type:         common.AI_SSE_Event_Error,
+					Error:        err.Error(),
+					GenerationID: generationID,
+					RequestID:    requestID,
+				})
+			}
+		})
+	default:
+		s.sendError(msg.RequestID, "不支持的消息类型: "+msg.Type)
+	}
+}
+
+// run 在后台执行一轮生成，把产生的事件带上请求ID交给写协程
+func (s *wsSession) run(requestID string, fn func(ctx context.Context, client chan<- *types.ChatSSEEvent) error) {
+	client := make(chan *types.ChatSSEEvent, eventBufferSize)
+	threading.GoSafe(func() {
+		// 由写入方关闭通道
+		defer close(client)
+		if err := fn(s.ctx, client); err != nil {
+			logc.Errorf(s.ctx, "ChatWsHandler: %v", err)
+		}
+	})
+	threading.GoSafe(func() {
+		for event := range client {
+			event.RequestID = requestID
+			s.send(event)
+		}
+	})
+}
+
+// send 把事件交给写协程，连接已关闭时丢弃
+func (s *wsSession) send(event *types.ChatSSEEvent) {
+	select {
+	case s.out <- event:
+	case <-s.closed:
+	}
+}
+
+func (s *wsSession) sendError(requestID string, errMsg string) {
+	s.send(&types.ChatSSEEvent{
+		Type:      common.AI_SSE_Event_Error,
+		Error:     errMsg,
+		RequestID: requestID,
+	})
+}
+
+// writeLoop 串行写出事件并定时发送心跳，写失败时关闭连接以结束读循环
+func (s *wsSession) writeLoop() {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-s.out:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteJSON(event); err != nil {
+				s.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				s.conn.Close()
+				return
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
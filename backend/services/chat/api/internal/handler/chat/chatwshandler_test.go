@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-roleplay/services/chat/api/internal/config"
+	"ai-roleplay/services/chat/api/internal/svc"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestChatWsRejectsForeignOrigin(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: config.Config{
+		WebSocket: config.WebSocketConfig{AllowedOrigins: []string{"https://app.example.com"}},
+	}}
+	server := httptest.NewServer(ChatWsHandler(svcCtx))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dial := func(origin string) (*websocket.Conn, *http.Response, error) {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		return websocket.DefaultDialer.Dial(wsURL, header)
+	}
+
+	conn, resp, err := dial("https://evil.example.com")
+	if err == nil {
+		conn.Close()
+		t.Fatal("connection from foreign origin accepted")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected response for foreign origin: %v, %v", resp, err)
+	}
+
+	for _, origin := range []string{"https://app.example.com", server.URL, ""} {
+		conn, _, err := dial(origin)
+		if err != nil {
+			t.Fatalf("connection from %q rejected: %v", origin, err)
+		}
+		conn.Close()
+	}
+}
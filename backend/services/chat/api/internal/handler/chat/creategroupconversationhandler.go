@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 创建多角色群聊
+func CreateGroupConversationHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateGroupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+			return
+		}
+
+		l := chat.NewCreateGroupConversationLogic(r.Context(), svcCtx)
+		resp, err := l.CreateGroupConversation(&req)
+		if err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		} else {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, resp)
+		}
+	}
+}
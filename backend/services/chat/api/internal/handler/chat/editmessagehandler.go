@@ -0,0 +1,28 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 编辑用户消息并重新生成回复（SSE），原消息作为旧分支保留
+func EditMessageHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.EditMessageRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+			return
+		}
+
+		serveSSE(w, r, svcCtx, func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+			return chat.NewEditMessageLogic(ctx, svcCtx).Sse(&req, client)
+		})
+	}
+}
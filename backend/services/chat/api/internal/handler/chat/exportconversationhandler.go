@@ -2,29 +2,38 @@ package chat
 
 import (
 	"net/http"
+	"strconv"
 
 	"ai-roleplay/services/chat/api/internal/logic/chat"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
 
+	"github.com/zeromicro/go-zero/core/logc"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-// 导出对话记录
+// 导出对话记录，按 format 返回对应格式的文件下载
 func ExportConversationHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req types.ConversationRequest
+		var req types.ExportRequest
 		if err := httpx.Parse(r, &req); err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 			return
 		}
 
 		l := chat.NewExportConversationLogic(r.Context(), svcCtx)
-		resp, err := l.ExportConversation(&req)
+		file, err := l.ExportConversation(&req)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
-		} else {
-			httpx.OkJsonCtx(r.Context(), w, resp)
+			return
+		}
+
+		w.Header().Set("Content-Type", file.ContentType)
+		w.Header().Set("Content-Disposition", file.ContentDisposition())
+		w.Header().Set("Content-Length", strconv.Itoa(len(file.Data)))
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(file.Data); err != nil {
+			logc.Errorf(r.Context(), "ExportConversationHandler: write failed: %v", err)
 		}
 	}
 }
@@ -0,0 +1,23 @@
+package chat
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/svc"
+
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 获取当前套餐的每日和每月token额度及用量
+func GetQuotaHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := chat.NewGetQuotaLogic(r.Context(), svcCtx)
+		resp, err := l.GetQuota()
+		if err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		} else {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/logic/chat"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 重新生成AI回复（SSE），新回复作为兄弟分支保存
+func RegenerateMessageHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.RegenerateRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+			return
+		}
+
+		serveSSE(w, r, svcCtx, func(ctx context.Context, client chan<- *types.ChatSSEEvent) error {
+			return chat.NewRegenerateMessageLogic(ctx, svcCtx).Sse(&req, client)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package chat
+
+import (
+	"net/http"
+
+	"ai-roleplay/services/chat/api/internal/generation"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	xhttp "github.com/zeromicro/x/http"
+)
+
+// 断线后续传生成的事件流（SSE），从 Last-Event-ID 或 last_seq 之后开始
+func ResumeGenerationHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ResumeGenerationRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+			return
+		}
+
+		afterSeq := req.LastSeq
+		if generationID, seq, ok := generation.ParseEventID(r.Header.Get("Last-Event-ID")); ok && generationID == req.ID {
+			afterSeq = seq
+		}
+
+		setSSEHeaders(w)
+		resumeSSE(w, r, svcCtx, req.ID, afterSeq)
+	}
+}
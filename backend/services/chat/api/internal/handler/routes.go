@@ -15,12 +15,30 @@ import (
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	server.AddRoutes(
 		[]rest.Route{
+			{
+				// 获取用户使用统计（兼容前端 /api/ai/usage）
+				Method:  http.MethodGet,
+				Path:    "/api/ai/usage",
+				Handler: chat.GetAiUsageHandler(serverCtx),
+			},
+			{
+				// 获取当前套餐的每日和每月token额度及用量
+				Method:  http.MethodGet,
+				Path:    "/api/ai/usage/quota",
+				Handler: chat.GetQuotaHandler(serverCtx),
+			},
 			{
 				// 侧边栏历史
 				Method:  http.MethodGet,
 				Path:    "/api/chat/before",
 				Handler: chat.ChatHistoryBeforeHandler(serverCtx),
 			},
+			{
+				// 给角色打1-5星评分，重复提交时覆盖
+				Method:  http.MethodPut,
+				Path:    "/api/chat/character/:id/rating",
+				Handler: chat.RateCharacterHandler(serverCtx),
+			},
 			{
 				// 创建新对话
 				Method:  http.MethodPost,
@@ -40,7 +58,7 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Handler: chat.DeleteConversationHandler(serverCtx),
 			},
 			{
-				// 导出对话记录
+				// 导出对话记录，按 format 返回对应格式的文件下载
 				Method:  http.MethodGet,
 				Path:    "/api/chat/conversation/:id/export",
 				Handler: chat.ExportConversationHandler(serverCtx),
@@ -51,6 +69,24 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/chat/conversation/:id/messages",
 				Handler: chat.ClearMessagesHandler(serverCtx),
 			},
+			{
+				// 获取群聊的参与角色
+				Method:  http.MethodGet,
+				Path:    "/api/chat/conversation/:id/participants",
+				Handler: chat.GetParticipantsHandler(serverCtx),
+			},
+			{
+				// 获取对话摘要
+				Method:  http.MethodGet,
+				Path:    "/api/chat/conversation/:id/summary",
+				Handler: chat.GetConversationSummaryHandler(serverCtx),
+			},
+			{
+				// 重新生成对话摘要
+				Method:  http.MethodPost,
+				Path:    "/api/chat/conversation/:id/summary",
+				Handler: chat.RegenerateConversationSummaryHandler(serverCtx),
+			},
 			{
 				// 更新对话标题
 				Method:  http.MethodPut,
@@ -69,36 +105,138 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/chat/conversations/batch-delete",
 				Handler: chat.BatchDeleteConversationsHandler(serverCtx),
 			},
+			{
+				// 导出消息反馈及对应的问题和回复，用于调整提示词
+				Method:  http.MethodGet,
+				Path:    "/api/chat/feedback/export",
+				Handler: chat.ExportFeedbackHandler(serverCtx),
+			},
+			{
+				// 停止正在进行的生成
+				Method:  http.MethodPost,
+				Path:    "/api/chat/generation/:id/cancel",
+				Handler: chat.CancelGenerationHandler(serverCtx),
+			},
+			{
+				// 断线后续传生成的事件流（SSE），从 Last-Event-ID 或 last_seq 之后开始
+				Method:  http.MethodGet,
+				Path:    "/api/chat/generation/:id/stream",
+				Handler: chat.ResumeGenerationHandler(serverCtx),
+			},
+			{
+				// 创建多角色群聊
+				Method:  http.MethodPost,
+				Path:    "/api/chat/group",
+				Handler: chat.CreateGroupConversationHandler(serverCtx),
+			},
 			{
 				// 获取对话历史
 				Method:  http.MethodPost,
 				Path:    "/api/chat/history",
 				Handler: chat.GetConversationHistoryHandler(serverCtx),
 			},
+			{
+				// 获取角色记住的关于我的信息
+				Method:  http.MethodGet,
+				Path:    "/api/chat/memories",
+				Handler: chat.GetMemoriesHandler(serverCtx),
+			},
+			{
+				// 修改一条记忆
+				Method:  http.MethodPut,
+				Path:    "/api/chat/memory/:id",
+				Handler: chat.UpdateMemoryHandler(serverCtx),
+			},
+			{
+				// 删除一条记忆
+				Method:  http.MethodDelete,
+				Path:    "/api/chat/memory/:id",
+				Handler: chat.DeleteMemoryHandler(serverCtx),
+			},
 			{
 				// 发送消息
 				Method:  http.MethodPost,
 				Path:    "/api/chat/message",
 				Handler: chat.SendMessageHandler(serverCtx),
 			},
+			{
+				// 选中消息所在的分支
+				Method:  http.MethodPut,
+				Path:    "/api/chat/message/:id/active",
+				Handler: chat.SetActiveBranchHandler(serverCtx),
+			},
+			{
+				// 编辑用户消息并重新生成回复（SSE），原消息作为旧分支保留
+				Method:  http.MethodGet,
+				Path:    "/api/chat/message/:id/edit",
+				Handler: chat.EditMessageHandler(serverCtx),
+			},
+			{
+				// 评价一条AI回复（赞或踩），可附带原因标签和补充说明，重复提交时覆盖
+				Method:  http.MethodPut,
+				Path:    "/api/chat/message/:id/feedback",
+				Handler: chat.SaveMessageFeedbackHandler(serverCtx),
+			},
+			{
+				// 撤回对AI回复的评价
+				Method:  http.MethodDelete,
+				Path:    "/api/chat/message/:id/feedback",
+				Handler: chat.DeleteMessageFeedbackHandler(serverCtx),
+			},
+			{
+				// 重新生成AI回复（SSE），新回复作为兄弟分支保存
+				Method:  http.MethodGet,
+				Path:    "/api/chat/message/:id/regenerate",
+				Handler: chat.RegenerateMessageHandler(serverCtx),
+			},
 			{
 				// 获取对话消息历史
 				Method:  http.MethodGet,
 				Path:    "/api/chat/messages",
 				Handler: chat.GetMessagesHandler(serverCtx),
 			},
+			{
+				// 列出被审核标记、遮蔽或拦截的消息，供人工复核
+				Method:  http.MethodGet,
+				Path:    "/api/chat/moderation/messages",
+				Handler: chat.GetModeratedMessagesHandler(serverCtx),
+			},
+			{
+				// 预览角色编译后的系统提示词
+				Method:  http.MethodGet,
+				Path:    "/api/chat/prompt/preview",
+				Handler: chat.GetPromptPreviewHandler(serverCtx),
+			},
 			{
 				// 搜索对话
 				Method:  http.MethodGet,
 				Path:    "/api/chat/search",
 				Handler: chat.SearchConversationsHandler(serverCtx),
 			},
+			{
+				// 全文检索消息内容，可按角色和日期筛选，返回带高亮的片段和消息ID
+				Method:  http.MethodGet,
+				Path:    "/api/chat/search/messages",
+				Handler: chat.SearchMessagesHandler(serverCtx),
+			},
 			{
 				// 发送消息并获取SSE流式响应
 				Method:  http.MethodGet,
 				Path:    "/api/chat/send",
 				Handler: chat.ChatSendHandler(serverCtx),
 			},
+			{
+				// 获取AI回复的token用量和耗时统计
+				Method:  http.MethodGet,
+				Path:    "/api/chat/usage",
+				Handler: chat.GetUsageHandler(serverCtx),
+			},
+			{
+				// WebSocket 聊天通道：一个连接上收发多个对话的消息，支持发送、停止、重新生成和心跳
+				Method:  http.MethodGet,
+				Path:    "/api/chat/ws",
+				Handler: chat.ChatWsHandler(serverCtx),
+			},
 		},
 	)
 }
@@ -0,0 +1,61 @@
+package history
+
+import (
+	"sort"
+
+	"ai-roleplay/services/chat/model"
+)
+
+// Siblings 按父消息分组，返回每组按分支序号排列的消息ID，对话的第一条消息归入键 0
+func Siblings(messages []model.Message) map[int64][]int64 {
+	groups := make(map[int64][]model.Message)
+	for _, message := range messages {
+		groups[parentKey(message)] = append(groups[parentKey(message)], message)
+	}
+
+	siblings := make(map[int64][]int64, len(groups))
+	for parent, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Branch < group[j].Branch
+		})
+		ids := make([]int64, 0, len(group))
+		for _, message := range group {
+			ids = append(ids, message.ID)
+		}
+		siblings[parent] = ids
+	}
+	return siblings
+}
+
+// ParentIDs 返回消息的父消息ID（去重），hasRoot 表示其中包含对话的首条消息
+func ParentIDs(messages []model.Message) (ids []int64, hasRoot bool) {
+	seen := make(map[int64]bool, len(messages))
+	for _, message := range messages {
+		if message.ParentID == nil {
+			hasRoot = true
+			continue
+		}
+		if !seen[*message.ParentID] {
+			seen[*message.ParentID] = true
+			ids = append(ids, *message.ParentID)
+		}
+	}
+	return ids, hasRoot
+}
+
+// TruncateBefore 截取路径中指定消息之前的部分，消息不在路径上时返回完整路径
+func TruncateBefore(path []model.Message, messageID int64) []model.Message {
+	for i, message := range path {
+		if message.ID == messageID {
+			return path[:i]
+		}
+	}
+	return path
+}
+
+func parentKey(message model.Message) int64 {
+	if message.ParentID == nil {
+		return 0
+	}
+	return *message.ParentID
+}
@@ -0,0 +1,52 @@
+package history
+
+import (
+	"testing"
+
+	"ai-roleplay/services/chat/model"
+)
+
+func parent(id int64) *int64 {
+	return &id
+}
+
+func TestSiblingsAndTruncate(t *testing.T) {
+	// 1(user) -> 2(ai, 旧分支) -> 3(user) -> 4(ai)
+	//         -> 5(ai, 重新生成并选中)
+	messages := []model.Message{
+		{ID: 1, IsActive: 1, Branch: 1},
+		{ID: 2, ParentID: parent(1), IsActive: 0, Branch: 1},
+		{ID: 3, ParentID: parent(2), IsActive: 1, Branch: 1},
+		{ID: 4, ParentID: parent(3), IsActive: 1, Branch: 1},
+		{ID: 5, ParentID: parent(1), IsActive: 1, Branch: 2},
+	}
+
+	siblings := Siblings(messages)
+	if ids := siblings[1]; len(ids) != 2 || ids[0] != 2 || ids[1] != 5 {
+		t.Fatalf("unexpected siblings of message 1: %v", ids)
+	}
+
+	// 当前路径为 1 -> 5
+	path := []model.Message{messages[0], messages[4]}
+	if truncated := TruncateBefore(path, 5); len(truncated) != 1 || truncated[0].ID != 1 {
+		t.Fatalf("unexpected truncated path: %+v", truncated)
+	}
+}
+
+func TestParentIDs(t *testing.T) {
+	page := []model.Message{
+		{ID: 1},
+		{ID: 5, ParentID: parent(1)},
+		{ID: 6, ParentID: parent(5)},
+		{ID: 7, ParentID: parent(5)},
+	}
+
+	ids, hasRoot := ParentIDs(page)
+	if !hasRoot || len(ids) != 2 || ids[0] != 1 || ids[1] != 5 {
+		t.Fatalf("unexpected parent ids: %v, hasRoot=%v", ids, hasRoot)
+	}
+
+	if ids, hasRoot := ParentIDs(page[1:2]); hasRoot || len(ids) != 1 {
+		t.Fatalf("unexpected parent ids: %v, hasRoot=%v", ids, hasRoot)
+	}
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// 分词器类型
+const (
+	TokenizerHeuristic = "heuristic" // 中文按字、英文按约4个字符估算
+	TokenizerRune      = "rune"      // 每个字符计为1个token，估算偏保守
+)
+
+// Tokenizer 计算文本的token数
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// NewTokenizer 按名称创建分词器，未知名称使用启发式分词器
+func NewTokenizer(name string) Tokenizer {
+	switch name {
+	case TokenizerRune:
+		return RuneTokenizer{}
+	default:
+		return HeuristicTokenizer{}
+	}
+}
+
+// HeuristicTokenizer 启发式估算：CJK字符约1个token，其余字符约4个一组
+type HeuristicTokenizer struct{}
+
+func (HeuristicTokenizer) CountTokens(text string) int {
+	cjk, others := 0, 0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else if !unicode.IsSpace(r) {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// RuneTokenizer 按字符数计算
+type RuneTokenizer struct{}
+
+func (RuneTokenizer) CountTokens(text string) int {
+	return utf8.RuneCountInString(text)
+}
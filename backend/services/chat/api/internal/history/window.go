@@ -0,0 +1,64 @@
+package history
+
+import (
+	"ai-roleplay/services/chat/model"
+)
+
+// messageOverhead 每条消息的角色标记等额外开销
+const messageOverhead = 4
+
+// WindowResult 历史窗口裁剪结果
+type WindowResult struct {
+	Budget          int   `json:"budget"`            // 历史可用的token预算
+	TotalMessages   int   `json:"total_messages"`    // 历史消息总数
+	KeptMessages    int   `json:"kept_messages"`     // 保留的消息数
+	DroppedMessages int   `json:"dropped_messages"`  // 被裁掉的消息数
+	HistoryTokens   int   `json:"history_tokens"`    // 保留部分的token数
+	CutoffMessageID int64 `json:"cutoff_message_id"` // 最早保留的消息ID，0 表示没有保留任何消息
+}
+
+// Window 按token预算从最新的消息向前保留历史
+type Window struct {
+	Budget    int
+	Tokenizer Tokenizer
+}
+
+func NewWindow(budget int, tokenizer Tokenizer) *Window {
+	if tokenizer == nil {
+		tokenizer = HeuristicTokenizer{}
+	}
+	return &Window{Budget: budget, Tokenizer: tokenizer}
+}
+
+// MessageTokens 计算单条消息占用的token数
+func (w *Window) MessageTokens(content string) int {
+	return w.Tokenizer.CountTokens(content) + messageOverhead
+}
+
+// Apply 裁剪按时间正序排列的消息，返回仍按正序排列的保留部分
+func (w *Window) Apply(messages []model.Message) ([]model.Message, *WindowResult) {
+	result := &WindowResult{
+		Budget:        w.Budget,
+		TotalMessages: len(messages),
+	}
+
+	start := len(messages)
+	used := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		tokens := w.MessageTokens(messages[i].Content)
+		if used+tokens > w.Budget {
+			break
+		}
+		used += tokens
+		start = i
+	}
+
+	kept := messages[start:]
+	result.KeptMessages = len(kept)
+	result.DroppedMessages = start
+	result.HistoryTokens = used
+	if len(kept) > 0 {
+		result.CutoffMessageID = kept[0].ID
+	}
+	return kept, result
+}
@@ -0,0 +1,41 @@
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"ai-roleplay/services/chat/model"
+)
+
+func TestHeuristicTokenizer(t *testing.T) {
+	tokenizer := HeuristicTokenizer{}
+	if n := tokenizer.CountTokens("你好世界"); n != 4 {
+		t.Fatalf("expected 4 tokens for CJK text, got %d", n)
+	}
+	if n := tokenizer.CountTokens("hello world"); n != 3 {
+		t.Fatalf("expected 3 tokens for latin text, got %d", n)
+	}
+}
+
+func TestWindowApply(t *testing.T) {
+	messages := []model.Message{
+		{ID: 1, Content: strings.Repeat("一", 20)},
+		{ID: 2, Content: strings.Repeat("二", 20)},
+		{ID: 3, Content: strings.Repeat("三", 20)},
+	}
+
+	// 每条消息 20 + 4 = 24 个token，预算只够保留最新的两条
+	window := NewWindow(50, RuneTokenizer{})
+	kept, result := window.Apply(messages)
+	if len(kept) != 2 || kept[0].ID != 2 || kept[1].ID != 3 {
+		t.Fatalf("unexpected kept messages: %+v", kept)
+	}
+	if result.DroppedMessages != 1 || result.CutoffMessageID != 2 || result.HistoryTokens != 48 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	kept, result = NewWindow(10, RuneTokenizer{}).Apply(messages)
+	if len(kept) != 0 || result.CutoffMessageID != 0 || result.DroppedMessages != 3 {
+		t.Fatalf("expected nothing kept, got %+v", result)
+	}
+}
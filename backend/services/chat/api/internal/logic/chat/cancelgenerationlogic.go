@@ -0,0 +1,39 @@
+package chat
+
+import (
+	"context"
+
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CancelGenerationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 停止正在进行的生成
+func NewCancelGenerationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelGenerationLogic {
+	return &CancelGenerationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CancelGenerationLogic) CancelGeneration(req *types.GenerationRequest) (resp *types.BaseResponse, err error) {
+	userId := int64(1)
+
+	if err := l.svcCtx.Generations.Cancel(l.ctx, req.ID, userId); err != nil {
+		return nil, err
+	}
+
+	l.Infof("User %d stopped generation %s", userId, req.ID)
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "已停止生成",
+	}, nil
+}
@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/group"
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/model"
+)
+
+// getSpeakerPrompt 选出群聊本轮回复的角色并编译其提示词，speakerId 为参与角色时直接由其回复
+func (l *ChatSendLogic) getSpeakerPrompt(conversation *model.Conversation, speakerId int64) (*prompt.CharacterPrompt, error) {
+	selector := group.NewSelector(l.ctx, l.svcCtx)
+	members, err := selector.Members(conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("群聊中没有可以发言的角色")
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	speaker, ok := group.Find(members, speakerId)
+	if !ok {
+		path, err := chatRepo.GetMessagesAfter(conversation.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		speaker = selector.Choose(conversation.TurnPolicy, members, path)
+	}
+	l.Infof("Group speaker chosen - ConversationId: %d, Policy: %s, CharacterId: %d",
+		conversation.ID, conversation.TurnPolicy, speaker.CharacterID)
+
+	character, err := chatRepo.GetCharacterByID(speaker.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("角色 %d 不存在", speaker.CharacterID)
+	}
+
+	names := make(map[int64]string, len(members))
+	for _, member := range members {
+		names[member.CharacterID] = member.Name
+	}
+	characterPrompt := prompt.BuildCharacterPrompt(character)
+	prompt.ApplyGroup(characterPrompt, names)
+	return characterPrompt, nil
+}
+
+// otherSpeaker 判断历史消息是否为群聊中其他角色的发言，是则返回其名称
+func otherSpeaker(characterPrompt *prompt.CharacterPrompt, message *model.Message) (string, bool) {
+	if len(characterPrompt.Members) == 0 || message.CharacterID == nil || *message.CharacterID == characterPrompt.CharacterID {
+		return "", false
+	}
+	name, ok := characterPrompt.Members[*message.CharacterID]
+	if !ok {
+		name = "其他角色"
+	}
+	return name, true
+}
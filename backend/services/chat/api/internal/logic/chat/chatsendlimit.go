@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/ratelimit"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	userRateKey    = "chat:ratelimit:user:%d"    // 用户的令牌桶
+	ipRateKey      = "chat:ratelimit:ip:%s"      // IP的令牌桶
+	userStreamsKey = "chat:ratelimit:streams:%d" // 用户进行中的生成
+)
+
+// acquireStream 按用户和IP限流，并限制用户同时进行的生成数，被拒绝时发送带重试时间的错误事件；
+// 返回的 release 在生成结束后调用，可重复调用。Redis 不可用时放行，不影响对话
+func (l *ChatSendLogic) acquireStream(client chan<- *types.ChatSSEEvent, userId int64) (func(), error) {
+	config := l.svcCtx.Config.RateLimit
+	l.release = func() {}
+	if !config.Enable {
+		return l.release, nil
+	}
+	limiter := l.svcCtx.Limiter
+
+	allowed, wait, err := limiter.Allow(l.ctx, fmt.Sprintf(userRateKey, userId), ratelimit.Bucket{Rate: config.UserRate, Burst: config.UserBurst})
+	if err != nil {
+		l.Errorf("Rate limit by user failed: %v", err)
+	} else if !allowed {
+		return nil, l.sendRejection(client, "user", "发送太频繁", wait)
+	}
+
+	if ip := ratelimit.ClientIP(l.ctx); ip != "" {
+		allowed, wait, err := limiter.Allow(l.ctx, fmt.Sprintf(ipRateKey, ip), ratelimit.Bucket{Rate: config.IPRate, Burst: config.IPBurst})
+		if err != nil {
+			l.Errorf("Rate limit by ip failed: %v", err)
+		} else if !allowed {
+			return nil, l.sendRejection(client, "ip", "当前网络发送太频繁", wait)
+		}
+	}
+
+	// 名额在最长生成时间后自动失效
+	key, member := fmt.Sprintf(userStreamsKey, userId), uuid.NewString()
+	allowed, wait, err = limiter.Acquire(l.ctx, key, member, config.MaxStreams, l.svcCtx.Config.Generation.MaxDuration+time.Minute)
+	if err != nil {
+		l.Errorf("Acquire stream slot failed: %v", err)
+		return l.release, nil
+	}
+	if !allowed {
+		return nil, l.sendRejection(client, "streams", fmt.Sprintf("最多同时进行%d个回复", config.MaxStreams), wait)
+	}
+	var once sync.Once
+	l.release = func() {
+		once.Do(func() {
+			if err := limiter.Release(context.Background(), key, member); err != nil {
+				logx.Errorf("Release stream slot failed - UserId: %d, Error: %v", userId, err)
+			}
+		})
+	}
+	return l.release, nil
+}
+
+// sendRejection 发送限流错误事件，retry_after 为建议等待的秒数
+func (l *ChatSendLogic) sendRejection(client chan<- *types.ChatSSEEvent, limit string, reason string, wait time.Duration) error {
+	retryAfter := max(int64(math.Ceil(wait.Seconds())), 1)
+	err := fmt.Errorf("%s，请%d秒后重试", reason, retryAfter)
+	l.Infof("Chat send rejected - Limit: %s, RetryAfter: %ds", limit, retryAfter)
+	l.sendEvent(client, &types.ChatSSEEvent{
+		Type:       common.AI_SSE_Event_Error,
+		Error:      err.Error(),
+		RetryAfter: retryAfter,
+		Metadata:   map[string]interface{}{"limit": limit},
+	})
+	return err
+}
@@ -2,16 +2,24 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"time"
 
+	"ai-roleplay/common/knowledge"
 	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/chat/api/internal/converter"
+	"ai-roleplay/services/chat/api/internal/generation"
+	"ai-roleplay/services/chat/api/internal/history"
+	"ai-roleplay/services/chat/api/internal/memory"
+	"ai-roleplay/services/chat/api/internal/moderation"
 	"ai-roleplay/services/chat/api/internal/prompt"
 	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/title"
 	"ai-roleplay/services/chat/api/internal/types"
 	"ai-roleplay/services/chat/model"
 
@@ -19,12 +27,19 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
 )
 
 type ChatSendLogic struct {
 	logx.Logger
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
+
+	generation *generation.Generation  // 登记后的事件会编号并写入缓冲
+	speaker    *prompt.CharacterPrompt // 本轮回复的角色，事件中标注由谁发言
+
+	release      func() // 释放生成名额，可重复调用
+	titlePending bool   // 完成事件后还要等待标题，事件流改由之后的 end 事件结束
 }
 
 // 发送消息并获取SSE流式响应
@@ -36,10 +51,18 @@ func NewChatSendLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChatSend
 	}
 }
 
-func (l *ChatSendLogic) ChatSend(req *types.ChatSendRequest) error {
-	// todo: add your logic here and delete this line
-
-	return nil
+// chatTurn 一轮回复生成所需的上下文
+type chatTurn struct {
+	conversationId  int64
+	userId          int64
+	userMessage     *model.Message        // 本轮回复所对应的用户消息，回复作为它的子消息保存
+	provider        *llm_model.Provider   // 本轮回复的提供方，发生切换时更新为实际回复的提供方
+	providers       []*llm_model.Provider // 按顺序尝试的提供方，第一个为选中的提供方
+	characterPrompt *prompt.CharacterPrompt
+	references      []knowledge.Hit
+	chatHistory     []*schema.Message
+	generation      *generation.Generation
+	ctx             context.Context // 本轮生成的 context，用户停止生成时被取消
 }
 
 func (l *ChatSendLogic) Sse(req *types.ChatSendRequest, client chan<- *types.ChatSSEEvent) error {
@@ -50,74 +73,279 @@ func (l *ChatSendLogic) Sse(req *types.ChatSendRequest, client chan<- *types.Cha
 	l.Infof("User %d starting SSE chat - ConversationId: %d, ContentLength: %d",
 		userId, req.ConversationId, len(req.Content))
 
+	// 限流并占用一个生成名额，回复结束后释放
+	release, err := l.acquireStream(client, userId)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := l.checkQuota(client, userId); err != nil {
+		return err
+	}
+
 	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
 
-	// 1、处理会话ID
-	conversationId := req.ConversationId
-	if req.ConversationId == 0 {
-		var err error
-		converter := converter.NewChatConverter()
-		conversation := converter.FromCreateConversationRequest(&types.CreateConversationRequest{
-			CharacterID: req.CharacterID,
-			Title:       "新对话",
-		})
+	// 1、审核用户消息，被拦截的消息不保存
+	content, verdict, err := l.moderateInput(client, req.Content)
+	if err != nil {
+		return err
+	}
 
-		err = chatRepo.CreateConversation(conversation)
+	// 2、保存用户消息，接在当前分支的最后一条消息之后
+	conversationId := req.ConversationId
+	userMessage := &model.Message{
+		ConversationID: conversationId,
+		Content:        content,
+		Type:           common.AI_Role_User,
+	}
+	if err := setInputModeration(userMessage, verdict); err != nil {
+		l.Errorf("Set message metadata failed: %v", err)
+	}
+	if conversationId == 0 {
+		conversationId, err = l.startConversation(client, userId, req.CharacterID, userMessage)
 		if err != nil {
-			l.sendError(client, fmt.Sprintf("创建对话失败: %v", err))
+			return err
+		}
+	} else {
+		last, err := chatRepo.GetLastPathMessage(conversationId)
+		if err != nil {
+			l.sendError(client, fmt.Sprintf("获取对话历史失败: %v", err))
+			return err
+		}
+		if last != nil {
+			userMessage.ParentID = &last.ID
+		}
+		if _, err := chatRepo.AddBranchMessage(userMessage); err != nil {
+			l.sendError(client, fmt.Sprintf("保存用户消息失败: %v", err))
 			return err
 		}
 	}
 
-	// 2、保存用户消息
-	_, err := chatRepo.AddMessage(&model.Message{
-		ConversationID: conversationId,
-		Content:        req.Content,
-		Type:           common.AI_Role_User,
-	})
+	// 3、准备上下文并流式生成回复
+	turn, err := l.prepareTurn(client, conversationId, userId, userMessage, req.Model, req.CharacterID, 0)
 	if err != nil {
-		l.sendError(client, fmt.Sprintf("保存用户消息失败: %v", err))
 		return err
 	}
+	return l.streamCallModelWithChannel(client, turn)
+}
+
+// startConversation 新建对话并保存第一条消息，再用第一个事件把新对话的ID告知客户端
+func (l *ChatSendLogic) startConversation(client chan<- *types.ChatSSEEvent, userId int64, characterId int64,
+	userMessage *model.Message) (int64, error) {
+	conversation := converter.NewChatConverter().FromCreateConversationRequest(&types.CreateConversationRequest{
+		CharacterID: characterId,
+	})
+	conversation.UserID = &userId
+
+	if err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).StartConversation(conversation, userMessage); err != nil {
+		l.sendError(client, fmt.Sprintf("创建对话失败: %v", err))
+		return 0, err
+	}
+	l.Infof("Conversation created - ConversationId: %d, CharacterId: %d", conversation.ID, characterId)
 
-	// 3、发送思考状态
 	l.sendEvent(client, &types.ChatSSEEvent{
-		Type:           common.AI_SSE_Event_Thinking,
-		Content:        req.Content,
-		ConversationID: conversationId,
+		Type:           common.AI_SSE_Event_Conversation,
+		ConversationID: conversation.ID,
+		MessageId:      userMessage.ID,
 	})
+	return conversation.ID, nil
+}
+
+// prepareTurn 选择模型、编译提示词、检索资料并裁剪历史，完成后发送思考状态；
+// speakerId 指定群聊中回复的角色，为0时按发言策略选择
+func (l *ChatSendLogic) prepareTurn(client chan<- *types.ChatSSEEvent, conversationId int64, userId int64,
+	userMessage *model.Message, modelName string, characterId int64, speakerId int64) (*chatTurn, error) {
+	// 1、按名称选择模型，未指定时使用默认提供方
+	provider, err := l.svcCtx.LLM.Get(modelName)
+	if err != nil {
+		l.sendError(client, fmt.Sprintf("获取模型失败: %v", err))
+		return nil, err
+	}
+
+	// 2、编译角色系统提示词
+	characterPrompt, err := l.getCharacterPrompt(conversationId, characterId, speakerId)
+	if err != nil {
+		l.sendError(client, fmt.Sprintf("获取角色信息失败: %v", err))
+		return nil, err
+	}
+	l.speaker = characterPrompt
+
+	// 3、检索角色知识库，失败时不引用资料继续对话
+	references := l.retrieveKnowledge(characterPrompt.CharacterID, userMessage.Content)
 
-	// 4、获取对话历史
-	chatHistory, err := l.getChatHistory(conversationId)
+	// 4、按token预算获取对话历史（不含本次用户消息）
+	chatHistory, window, err := l.getChatHistory(conversationId, userMessage.ID, userId, provider, characterPrompt, userMessage.Content, references)
 	if err != nil {
 		l.sendError(client, fmt.Sprintf("获取对话历史失败: %v", err))
-		return err
+		return nil, err
+	}
+
+	// 5、登记生成任务，客户端凭生成ID停止生成
+	gen, ctx, err := l.svcCtx.Generations.Register(l.ctx, conversationId, userId)
+	if err != nil {
+		l.sendError(client, fmt.Sprintf("登记生成任务失败: %v", err))
+		return nil, err
 	}
+	l.generation = gen
 
-	// 5、调用LLM流式生成
-	return l.streamCallModelWithChannel(client, req, chatHistory, conversationId, userId)
+	// 6、发送思考状态，附带生成ID和历史裁剪信息
+	l.sendEvent(client, &types.ChatSSEEvent{
+		Type:           common.AI_SSE_Event_Thinking,
+		Content:        userMessage.Content,
+		ConversationID: conversationId,
+		GenerationID:   gen.ID,
+		Metadata: map[string]interface{}{
+			"history_window": window,
+		},
+	})
+
+	return &chatTurn{
+		conversationId:  conversationId,
+		userId:          userId,
+		userMessage:     userMessage,
+		provider:        provider,
+		providers:       l.svcCtx.LLM.Chain(provider, characterPrompt.Providers),
+		characterPrompt: characterPrompt,
+		references:      references,
+		chatHistory:     chatHistory,
+		generation:      gen,
+		ctx:             ctx,
+	}, nil
+}
+
+// retrieveKnowledge 检索与本轮问题相关的角色资料
+func (l *ChatSendLogic) retrieveKnowledge(characterId int64, question string) []knowledge.Hit {
+	config := l.svcCtx.Config.Knowledge
+	if !config.Enable || characterId <= 0 {
+		return nil
+	}
+
+	hits, err := l.svcCtx.Knowledge.Search(l.ctx, characterId, question, config.TopK, config.MinScore)
+	if err != nil {
+		l.Errorf("Retrieve knowledge failed - CharacterId: %d, Error: %v", characterId, err)
+		return nil
+	}
+	return hits
 }
 
-func (l *ChatSendLogic) getChatHistory(conversation_id int64) ([]*schema.Message, error) {
+// getCharacterPrompt 优先使用对话绑定的角色，新对话则使用请求中的角色；群聊由发言策略选出回复的角色
+func (l *ChatSendLogic) getCharacterPrompt(conversationId int64, characterId int64, speakerId int64) (*prompt.CharacterPrompt, error) {
 	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
-	chatHistory, err := chatRepo.GetConversationMessages(conversation_id)
+	if conversationId > 0 {
+		conversation, err := chatRepo.GetConversationByID(conversationId)
+		if err != nil {
+			return nil, err
+		}
+		if conversation != nil && conversation.IsGroup() {
+			return l.getSpeakerPrompt(conversation, speakerId)
+		}
+		if conversation != nil {
+			characterId = conversation.CharacterID
+		}
+	}
+
+	character, err := chatRepo.GetCharacterByID(characterId)
 	if err != nil {
 		return nil, err
 	}
-	var messages []*schema.Message
-	for _, message := range chatHistory {
+	if character == nil {
+		l.Infof("Character %d not found, using default persona", characterId)
+	}
+	return prompt.BuildCharacterPrompt(character), nil
+}
+
+// getChatHistory 按时间顺序读取历史，并在模型上下文窗口内为系统提示词、长期记忆、角色资料、摘要和回复预留空间后裁剪
+func (l *ChatSendLogic) getChatHistory(conversationId int64, currentMessageId int64, userId int64, provider *llm_model.Provider,
+	characterPrompt *prompt.CharacterPrompt, question string, references []knowledge.Hit) ([]*schema.Message, *history.WindowResult, error) {
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+
+	// 已被摘要覆盖的消息不再读取原文，改为在历史前插入摘要
+	summary, err := chatRepo.GetConversationSummary(conversationId)
+	if err != nil {
+		return nil, nil, err
+	}
+	var coveredMessageId int64
+	if summary != nil {
+		coveredMessageId = summary.CoveredMessageID
+	}
+
+	chatHistory, err := chatRepo.GetMessagesAfter(conversationId, coveredMessageId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 本次用户消息由模板单独追加，重新生成时其后的旧回复也不应出现，这里一并截掉
+	previous := history.TruncateBefore(chatHistory, currentMessageId)
+
+	window := history.NewWindow(0, provider.Tokenizer)
+	budget := provider.ContextWindow - provider.ReplyReserve() -
+		window.MessageTokens(characterPrompt.Policy) -
+		window.MessageTokens(characterPrompt.Persona) -
+		window.MessageTokens(question)
+	var memoryMsg *schema.Message
+	if l.svcCtx.Config.Memory.Enable && characterPrompt.CharacterID > 0 {
+		// 长期记忆是可选的，查询失败时不带记忆继续对话
+		facts, err := memory.NewStore(l.ctx, l.svcCtx).Retrieve(userId, characterPrompt.CharacterID, question, l.svcCtx.Config.Memory.RetrieveLimit)
+		if err != nil {
+			l.Errorf("Retrieve memories failed: %v", err)
+		}
+		if len(facts) > 0 {
+			memoryMsg = prompt.MemoryMessage(facts)
+			budget -= window.MessageTokens(memoryMsg.Content)
+		}
+	}
+	var knowledgeMsg *schema.Message
+	if len(references) > 0 {
+		knowledgeMsg = prompt.KnowledgeMessage(references)
+		budget -= window.MessageTokens(knowledgeMsg.Content)
+	}
+	var summaryMsg *schema.Message
+	if summary != nil {
+		summaryMsg = prompt.SummaryMessage(summary.Summary)
+		budget -= window.MessageTokens(summaryMsg.Content)
+	}
+	if budget < 0 {
+		budget = 0
+	}
+	window.Budget = budget
+
+	kept, result := window.Apply(previous)
+	if result.DroppedMessages > 0 {
+		l.Infof("History trimmed - ConversationId: %d, Kept: %d, Dropped: %d, Budget: %d",
+			conversationId, result.KeptMessages, result.DroppedMessages, result.Budget)
+	}
+
+	messages := make([]*schema.Message, 0, len(kept)+3)
+	if memoryMsg != nil {
+		messages = append(messages, memoryMsg)
+	}
+	if knowledgeMsg != nil {
+		messages = append(messages, knowledgeMsg)
+	}
+	if summaryMsg != nil {
+		messages = append(messages, summaryMsg)
+	}
+	for _, message := range kept {
 		role := convertRoleForLLM(message.Type)
+		content := message.Content
+		// 群聊中其他角色的发言标注名字后作为用户侧消息，只有自己的发言作为 assistant
+		if name, ok := otherSpeaker(characterPrompt, &message); ok {
+			role = common.AI_Role_User
+			content = prompt.SpeakerContent(name, content)
+		}
 		msg := &schema.Message{
 			Role:    schema.RoleType(role),
-			Content: message.Content,
+			Content: content,
 		}
 		messages = append(messages, msg)
 	}
-	return messages, nil
+	return messages, result, nil
 }
+
 func convertRoleForLLM(role string) string {
 	switch role {
-	case "ai":
+	case common.Message_Type_AI:
 		return "assistant" // 将 "ai" 转换为 "assistant"
 	case "user":
 		return "user" // 保持不变
@@ -129,12 +357,28 @@ func convertRoleForLLM(role string) string {
 }
 
 func (l *ChatSendLogic) sendEvent(client chan<- *types.ChatSSEEvent, resp *types.ChatSSEEvent) {
+	// 生成登记后的事件先编号写入缓冲，客户端断线后可凭 Last-Event-ID 续传
+	if l.speaker != nil && l.speaker.CharacterID > 0 {
+		resp.CharacterID = l.speaker.CharacterID
+		resp.CharacterName = l.speaker.CharacterName
+	}
+	if l.generation != nil {
+		resp.GenerationID = l.generation.ID
+		resp.Seq = l.generation.NextSeq()
+		final := resp.Type == common.AI_SSE_Event_Error || resp.Type == common.AI_SSE_Event_End ||
+			(resp.Type == common.AI_SSE_Event_Done && !l.titlePending)
+		if data, err := json.Marshal(resp); err != nil {
+			l.Errorf("Marshal SSE event failed: %v", err)
+		} else if err := l.svcCtx.Generations.Append(l.ctx, resp.GenerationID, resp.Seq, data, final); err != nil {
+			l.Errorf("Append SSE event failed - GenerationId: %s, Seq: %d, Error: %v", resp.GenerationID, resp.Seq, err)
+		}
+	}
+
+	// 通道满时阻塞等待，由读取方的写出速度限制生成速度；读取方在客户端断开后仍会取走事件直到通道关闭，不会一直阻塞
 	select {
 	case client <- resp:
 	case <-l.ctx.Done():
 		l.Info("Context cancelled, stopping event send")
-	default:
-		l.Error("Channel full, dropping event")
 	}
 }
 
@@ -146,69 +390,156 @@ func (l *ChatSendLogic) sendError(client chan<- *types.ChatSSEEvent, errMsg stri
 	})
 }
 
-func (l *ChatSendLogic) streamCallModelWithChannel(client chan<- *types.ChatSSEEvent, req *types.ChatSendRequest, chatHistory []*schema.Message, conversationId int64, userId int64) error {
-	// 设置超时
-	ctx, cancel := context.WithTimeout(l.ctx, 60*time.Second)
-	defer cancel()
+func (l *ChatSendLogic) streamCallModelWithChannel(client chan<- *types.ChatSSEEvent, turn *chatTurn) error {
+	conversationId := turn.conversationId
+	references := turn.references
 
-	// 创建模型
+	defer l.svcCtx.Generations.Finish(turn.generation)
 
-	chatModel := llm_model.CreateDeepSeekChatModel(ctx)
-	promptMsg := prompt.CreateMessageFromTemplate(req.Content, chatHistory)
+	promptMsg, err := prompt.CreateMessageFromTemplate(turn.characterPrompt, turn.userMessage.Content, turn.chatHistory)
+	if err != nil {
+		l.sendError(client, fmt.Sprintf("构建提示词失败: %v", err))
+		return err
+	}
 
-	// 开始流式生成
+	// 开始流式生成，角色配置了工具时模型可以先调用工具，再根据结果继续回答
 	l.Info("Starting LLM stream generation")
-	streamReader := prompt.GenerateStream(ctx, chatModel, promptMsg)
-	defer streamReader.Close()
-
-	var fullContent strings.Builder
-
-	for {
-		select {
-		case <-ctx.Done():
-			l.sendError(client, "请求超时")
-			return ctx.Err()
-		default:
-		}
+	toolset, toolInfos, err := l.bindTools(turn)
+	if err != nil {
+		l.sendError(client, fmt.Sprintf("加载工具失败: %v", err))
+		return err
+	}
 
-		recv, err := streamReader.Recv()
-		if err == io.EOF {
-			l.Info("LLM stream completed")
-			break
+	state := &streamState{startedAt: time.Now()}
+	if l.svcCtx.Config.Moderation.Enable {
+		state.filter = l.svcCtx.Moderation.NewStreamFilter(l.ctx)
+	}
+	messages := promptMsg
+	for round := 0; ; round++ {
+		// 达到工具调用次数上限后不再提供工具，要求模型直接回答
+		if round == l.svcCtx.Config.Tools.MaxRounds {
+			toolInfos = nil
 		}
+		reply, err := l.streamRound(client, turn, toolInfos, messages, state)
 		if err != nil {
-			l.Errorf("LLM stream error: %v", err)
-			l.sendError(client, fmt.Sprintf("接收流式数据失败: %v", err))
 			return err
 		}
+		if state.stopped || state.blocked || state.incomplete != "" || len(reply.ToolCalls) == 0 {
+			break
+		}
 
-		if recv.Content != "" {
-			fullContent.WriteString(recv.Content)
-
-			// 只发送增量内容，避免重复数据
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			messages = append(messages, l.runTool(client, turn, toolset, call, state))
+		}
+		if errors.Is(context.Cause(turn.ctx), generation.ErrStopped) {
+			state.stopped = true
+			break
+		}
+	}
+	if state.stopped {
+		l.Infof("LLM stream stopped by user - GenerationId: %s", turn.generation.ID)
+	}
+	// 审核过滤保留的末尾内容在输出结束后发送
+	if state.filter != nil && !state.blocked {
+		if rest := state.filter.Flush(); rest != "" {
 			l.sendEvent(client, &types.ChatSSEEvent{
 				Type:           common.AI_SSE_Event_Message,
-				Content:        recv.Content, // 只发送当前增量内容
+				Content:        rest,
 				ConversationID: conversationId,
 			})
 		}
 	}
 
-	finalContent := fullContent.String()
+	// 审核完整回复，遮蔽或拦截后的内容作为最终回复保存
+	finalContent, verdict := l.moderateOutput(state.content.String())
+	latencyMs := time.Since(state.startedAt).Milliseconds()
+	usage := &state.usage
 	l.Infof("Final content length: %d", len(finalContent))
 
-	// 保存AI回复到数据库
-	msgService := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
-	msgId, err := msgService.AddMessage(&model.Message{
+	// 按实际用量扣减额度，停止生成的回复同样计入
+	l.consumeQuota(turn.userId, usage.TotalTokens)
+
+	// 保存AI回复到数据库，作为用户消息的新分支；引用的角色资料记录在元数据中
+	aiMessage := &model.Message{
 		ConversationID: conversationId,
+		ParentID:       &turn.userMessage.ID,
 		Type:           common.AI_Role_Assistant,
 		Content:        finalContent,
-	})
+		TokenUsed:      int32(usage.TotalTokens),
+		ProcessingTime: int32(latencyMs),
+	}
+	if characterId := turn.characterPrompt.CharacterID; characterId > 0 {
+		aiMessage.CharacterID = &characterId
+	}
+	metadata := map[string]interface{}{
+		"provider":          turn.provider.Name,
+		"model":             turn.provider.Model,
+		"prompt_tokens":     usage.PromptTokens,
+		"completion_tokens": usage.CompletionTokens,
+		"first_token_ms":    state.firstTokenMs,
+	}
+	if state.finishReason != "" {
+		metadata["finish_reason"] = state.finishReason
+	}
+	if state.usageEstimated {
+		metadata["usage_estimated"] = true
+	}
+	if len(state.toolCalls) > 0 {
+		metadata["tool_calls"] = state.toolCalls
+	}
+	if state.failover {
+		metadata["provider_attempts"] = state.attempts
+	}
+	if len(references) > 0 {
+		metadata["citations"] = knowledge.Citations(references)
+	}
+	if state.stopped {
+		metadata["stopped"] = true
+	}
+	if state.incomplete != "" {
+		metadata["incomplete"] = true
+		metadata["incomplete_reason"] = state.incomplete
+	}
+	if verdict != nil {
+		metadata["moderation"] = verdict
+		setOriginalContent(aiMessage, verdict)
+	}
+	if err := converter.NewChatConverter().SetMessageMetadata(aiMessage, metadata); err != nil {
+		l.Errorf("Set message metadata failed: %v", err)
+	}
+	msgService := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	msgId, err := msgService.AddBranchMessage(aiMessage)
 	if err != nil {
 		l.sendError(client, fmt.Sprintf("保存AI消息失败: %v", err))
 		return err
 	}
 
+	// 异步压缩超出窗口的旧消息
+	if l.svcCtx.Config.Summary.Enable {
+		threading.GoSafe(func() {
+			summarizer := memory.NewSummarizer(context.Background(), l.svcCtx)
+			if err := summarizer.MaybeSummarize(conversationId); err != nil {
+				logx.Errorf("MaybeSummarize failed - ConversationId: %d, Error: %v", conversationId, err)
+			}
+		})
+	}
+
+	// 异步提取关于用户的长期记忆
+	if l.svcCtx.Config.Memory.Enable && turn.characterPrompt.CharacterID > 0 {
+		userId, characterId, question := turn.userId, turn.characterPrompt.CharacterID, turn.userMessage.Content
+		threading.GoSafe(func() {
+			store := memory.NewStore(context.Background(), l.svcCtx)
+			if err := store.Extract(userId, characterId, msgId, question, finalContent); err != nil {
+				logx.Errorf("Extract memory failed - MessageId: %d, Error: %v", msgId, err)
+			}
+		})
+	}
+
+	// 第一轮回复后异步生成标题，在完成事件之后推送
+	titleCh := l.startTitle(conversationId, turn.userMessage.Content, finalContent)
+	l.titlePending = titleCh != nil
+
 	// 发送完成事件
 	l.sendEvent(client, &types.ChatSSEEvent{
 		Type:           common.AI_SSE_Event_Done,
@@ -216,8 +547,216 @@ func (l *ChatSendLogic) streamCallModelWithChannel(client chan<- *types.ChatSSEE
 		MessageId:      msgId,
 		Content:        finalContent,
 		ConversationID: conversationId,
+		Metadata:       metadata,
+		TokensIn:       int64(usage.PromptTokens),
+		TokensOut:      int64(usage.CompletionTokens),
+		LatencyMs:      latencyMs,
+		GenerationID:   turn.generation.ID,
+		ParentID:       turn.userMessage.ID,
+		Branch:         int(aiMessage.Branch),
 	})
 
+	// 回复已结束，等待标题前先释放生成名额
+	if l.release != nil {
+		l.release()
+	}
+	l.waitTitle(client, conversationId, titleCh)
+
 	l.Info("SSE stream completed successfully")
 	return nil
 }
+
+// startTitle 对话仍为默认标题时在后台生成标题，不需要时返回 nil
+func (l *ChatSendLogic) startTitle(conversationId int64, question, reply string) <-chan string {
+	if !l.svcCtx.Config.Title.Enable {
+		return nil
+	}
+	conversation, err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).GetConversationByID(conversationId)
+	if err != nil || !title.NeedsTitle(conversation) {
+		return nil
+	}
+
+	titleCh := make(chan string, 1)
+	timeout := l.svcCtx.Config.Title.Timeout
+	threading.GoSafe(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		newTitle, err := title.NewGenerator(ctx, l.svcCtx).Generate(conversationId, question, reply)
+		if err != nil {
+			logx.Errorf("Generate title failed - ConversationId: %d, Error: %v", conversationId, err)
+		}
+		titleCh <- newTitle
+	})
+	return titleCh
+}
+
+// waitTitle 等待标题生成完成并推送 title 事件，超时或未生成时不推送；最后发送 end 事件结束事件流，
+// 断线续传的客户端也能收到标题
+func (l *ChatSendLogic) waitTitle(client chan<- *types.ChatSSEEvent, conversationId int64, titleCh <-chan string) {
+	if titleCh == nil {
+		return
+	}
+	defer l.sendEvent(client, &types.ChatSSEEvent{
+		Type:           common.AI_SSE_Event_End,
+		ConversationID: conversationId,
+	})
+	select {
+	case newTitle := <-titleCh:
+		if newTitle != "" {
+			l.sendEvent(client, &types.ChatSSEEvent{
+				Type:           common.AI_SSE_Event_Title,
+				ConversationID: conversationId,
+				Title:          newTitle,
+			})
+		}
+	case <-time.After(l.svcCtx.Config.Title.Timeout):
+		l.Infof("Title generation timed out - ConversationId: %d", conversationId)
+	}
+}
+
+// streamState 一轮回复在多次模型调用（工具调用前后）之间累计的状态
+type streamState struct {
+	startedAt      time.Time
+	content        strings.Builder
+	usage          schema.TokenUsage
+	usageEstimated bool
+	finishReason   string
+	firstTokenMs   int64
+	stopped        bool
+	blocked        bool   // 输出命中拦截规则，已停止生成
+	incomplete     string // 超时或中途出错时未完成的原因，已输出的部分仍会保存
+	toolCalls      []types.ToolCallInfo
+	filter         *moderation.StreamFilter
+	attempts       []llm_model.Attempt // 各次模型调用尝试过的提供方
+	failover       bool                // 是否发生过提供方切换
+}
+
+// streamRound 调用一次模型并转发增量内容，返回拼接后的完整消息（可能包含工具调用）；
+// 提供方在首个分片前失败或超时时切换到下一个备用提供方
+func (l *ChatSendLogic) streamRound(client chan<- *types.ChatSSEEvent, turn *chatTurn, toolInfos []*schema.ToolInfo,
+	messages []*schema.Message, state *streamState) (*schema.Message, error) {
+	// 用户停止或超过最长生成时间时 ctx 会被取消
+	ctx := turn.ctx
+	streamReader, attempts, err := llm_model.StreamWithFailover(ctx, turn.providers, messages, toolInfos, l.svcCtx.Config.LLM.FirstTokenTimeout)
+	state.attempts = append(state.attempts, attempts...)
+	if len(attempts) > 1 {
+		state.failover = true
+		l.Infof("LLM provider failover - GenerationId: %s, Attempts: %+v", turn.generation.ID, attempts)
+	}
+	if errors.Is(context.Cause(ctx), generation.ErrStopped) {
+		state.stopped = true
+		return schema.AssistantMessage("", nil), nil
+	}
+	if err != nil {
+		l.Errorf("LLM stream error: %v", err)
+		l.sendError(client, fmt.Sprintf("调用模型失败: %v", err))
+		return nil, err
+	}
+	defer streamReader.Close()
+	turn.provider = streamReader.Provider
+
+	var chunks []*schema.Message
+	var usage *schema.TokenUsage
+	for {
+		recv, err := streamReader.Recv()
+		if err == io.EOF {
+			l.Info("LLM stream completed")
+			break
+		}
+
+		if err == nil {
+			chunks = append(chunks, recv)
+
+			// 用量和结束原因通常在最后一个分片的响应元信息中
+			if recv.ResponseMeta != nil {
+				if recv.ResponseMeta.Usage != nil {
+					usage = recv.ResponseMeta.Usage
+				}
+				if recv.ResponseMeta.FinishReason != "" {
+					state.finishReason = recv.ResponseMeta.FinishReason
+				}
+			}
+
+			if recv.Content != "" {
+				if state.firstTokenMs == 0 {
+					state.firstTokenMs = time.Since(state.startedAt).Milliseconds()
+				}
+				state.content.WriteString(recv.Content)
+
+				// 增量内容先经过审核过滤，命中拦截规则时停止生成
+				delta, ok := state.filterDelta(recv.Content)
+				if !ok {
+					l.Infof("LLM stream blocked by moderation - GenerationId: %s", turn.generation.ID)
+					state.blocked = true
+					break
+				}
+
+				// 只发送增量内容，避免重复数据
+				if delta != "" {
+					l.sendEvent(client, &types.ChatSSEEvent{
+						Type:           common.AI_SSE_Event_Message,
+						Content:        delta, // 只发送当前增量内容
+						ConversationID: turn.conversationId,
+					})
+				}
+			}
+		}
+
+		// 用户停止时保留已生成的部分，作为正常回复保存
+		if errors.Is(context.Cause(ctx), generation.ErrStopped) {
+			state.stopped = true
+			break
+		}
+		// 超时或中途出错时已输出的部分作为未完成的回复保存，没有输出时按失败处理
+		if ctx.Err() != nil {
+			if state.content.Len() > 0 {
+				l.Infof("LLM stream timed out, saving partial reply - GenerationId: %s", turn.generation.ID)
+				state.incomplete = "请求超时"
+				break
+			}
+			l.sendError(client, "请求超时")
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			l.Errorf("LLM stream error: %v", err)
+			if state.content.Len() > 0 {
+				state.incomplete = fmt.Sprintf("接收流式数据失败: %v", err)
+				break
+			}
+			l.sendError(client, fmt.Sprintf("接收流式数据失败: %v", err))
+			return nil, err
+		}
+	}
+
+	reply := schema.AssistantMessage("", nil)
+	if len(chunks) > 0 {
+		if reply, err = schema.ConcatMessages(chunks); err != nil {
+			l.sendError(client, fmt.Sprintf("解析模型输出失败: %v", err))
+			return nil, err
+		}
+	}
+	// 未完成的回复中工具调用参数可能不完整，不再执行
+	if state.incomplete != "" {
+		reply.ToolCalls = nil
+	}
+
+	// 模型未返回用量时（如中途停止）用分词器估算
+	if usage == nil {
+		usage = estimateUsage(turn.provider.Tokenizer, messages, reply.Content)
+		state.usageEstimated = true
+	}
+	state.usage.PromptTokens += usage.PromptTokens
+	state.usage.CompletionTokens += usage.CompletionTokens
+	state.usage.TotalTokens += usage.TotalTokens
+	return reply, nil
+}
+
+// estimateUsage 按分词器估算本轮的token用量
+func estimateUsage(tokenizer history.Tokenizer, promptMsg []*schema.Message, reply string) *schema.TokenUsage {
+	usage := &schema.TokenUsage{CompletionTokens: tokenizer.CountTokens(reply)}
+	for _, msg := range promptMsg {
+		usage.PromptTokens += tokenizer.CountTokens(msg.Content)
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
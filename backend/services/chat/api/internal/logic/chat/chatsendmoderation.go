@@ -0,0 +1,82 @@
+package chat
+
+import (
+	"fmt"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/converter"
+	"ai-roleplay/services/chat/api/internal/moderation"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+)
+
+// blockedReply 回复被拦截时保存和推送的内容
+const blockedReply = "（该回复包含不适宜的内容，已被屏蔽）"
+
+// moderateInput 审核用户消息，返回处理后的内容和审核结论；被拦截时发送错误事件并返回错误
+func (l *ChatSendLogic) moderateInput(client chan<- *types.ChatSSEEvent, content string) (string, *moderation.Verdict, error) {
+	if !l.svcCtx.Config.Moderation.Enable {
+		return content, nil, nil
+	}
+	verdict, result := l.svcCtx.Moderation.Check(l.ctx, moderation.StageInput, content)
+	if verdict == nil {
+		return content, nil, nil
+	}
+
+	l.Infof("Input moderated - Action: %s, Findings: %d", verdict.Action, len(verdict.Findings))
+	if verdict.Action == moderation.ActionBlock {
+		err := fmt.Errorf("消息包含不适宜的内容，请修改后重试")
+		l.sendEvent(client, &types.ChatSSEEvent{
+			Type:     common.AI_SSE_Event_Error,
+			Error:    err.Error(),
+			Metadata: map[string]interface{}{"moderation": verdict},
+		})
+		return "", verdict, err
+	}
+	return result, verdict, nil
+}
+
+// moderateOutput 审核完整回复，被拦截时替换为提示语
+func (l *ChatSendLogic) moderateOutput(content string) (string, *moderation.Verdict) {
+	if !l.svcCtx.Config.Moderation.Enable || content == "" {
+		return content, nil
+	}
+	verdict, result := l.svcCtx.Moderation.Check(l.ctx, moderation.StageOutput, content)
+	if verdict == nil {
+		return content, nil
+	}
+
+	l.Infof("Output moderated - Action: %s, Findings: %d", verdict.Action, len(verdict.Findings))
+	if verdict.Action == moderation.ActionBlock {
+		return blockedReply, verdict
+	}
+	return result, verdict
+}
+
+// setInputModeration 把用户消息的审核结论写入元数据，原文单独保存
+func setInputModeration(message *model.Message, verdict *moderation.Verdict) error {
+	if verdict == nil {
+		return nil
+	}
+	setOriginalContent(message, verdict)
+	return converter.NewChatConverter().SetMessageMetadata(message, map[string]interface{}{
+		"moderation": verdict,
+	})
+}
+
+// setOriginalContent 内容被遮蔽或拦截时把原文保存到消息的 original_content，不写入元数据
+func setOriginalContent(message *model.Message, verdict *moderation.Verdict) {
+	if verdict == nil || verdict.Original == "" {
+		return
+	}
+	original := verdict.Original
+	message.OriginalContent = &original
+}
+
+// filterDelta 审核流式输出的增量，返回可以发送的部分；命中拦截规则时返回 false
+func (s *streamState) filterDelta(delta string) (string, bool) {
+	if s.filter == nil {
+		return delta, true
+	}
+	return s.filter.Write(delta)
+}
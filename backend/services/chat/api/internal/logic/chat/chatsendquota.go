@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+// checkQuota 在调用模型前检查套餐额度，用完时发送错误事件，retry_after 为距离额度重置的秒数；
+// 读取额度失败时放行，不影响对话
+func (l *ChatSendLogic) checkQuota(client chan<- *types.ChatSSEEvent, userId int64) error {
+	if !l.svcCtx.Config.Quota.Enable {
+		return nil
+	}
+	usage, err := l.svcCtx.Quota.Get(l.ctx, userId)
+	if err != nil {
+		l.Errorf("Get quota failed: %v", err)
+		return nil
+	}
+	period, exceeded := usage.Exceeded()
+	if !exceeded {
+		return nil
+	}
+
+	name := "今日"
+	if period == &usage.Monthly {
+		name = "本月"
+	}
+	retryAfter := max(int64(time.Until(period.ResetAt).Seconds()), 1)
+	err = fmt.Errorf("%s额度已用完（%s套餐 %d tokens），将于 %s 重置",
+		name, usage.Plan, period.Limit, period.ResetAt.Format("2006-01-02 15:04"))
+	l.Infof("Chat send rejected - Quota: %s, Plan: %s, Used: %d", name, usage.Plan, period.Used)
+	l.sendEvent(client, &types.ChatSSEEvent{
+		Type:       common.AI_SSE_Event_Error,
+		Error:      err.Error(),
+		RetryAfter: retryAfter,
+		Metadata:   map[string]interface{}{"quota": toQuotaResponse(usage)},
+	})
+	return err
+}
+
+// consumeQuota 按本轮回复的实际用量异步扣减额度
+func (l *ChatSendLogic) consumeQuota(userId int64, tokens int) {
+	if !l.svcCtx.Config.Quota.Enable || tokens <= 0 {
+		return
+	}
+	threading.GoSafe(func() {
+		if err := l.svcCtx.Quota.Consume(context.Background(), userId, int64(tokens)); err != nil {
+			logx.Errorf("Consume quota failed - UserId: %d, Tokens: %d, Error: %v", userId, tokens, err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/tools"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// bindTools 按角色配置创建工具，返回工具集和交给模型的工具描述，角色没有可用工具时都为空；
+// 工具在每次调用时绑定到实际使用的提供方
+func (l *ChatSendLogic) bindTools(turn *chatTurn) (map[string]tool.InvokableTool, []*schema.ToolInfo, error) {
+	if !l.svcCtx.Config.Tools.Enable || len(turn.characterPrompt.Tools) == 0 {
+		return nil, nil, nil
+	}
+
+	resolved, unknown, err := l.svcCtx.Tools.Resolve(turn.characterPrompt.Tools, tools.Scope{
+		CharacterID: turn.characterPrompt.CharacterID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(unknown) > 0 {
+		l.Infof("Skip unavailable tools - CharacterId: %d, Tools: %v", turn.characterPrompt.CharacterID, unknown)
+	}
+	if len(resolved) == 0 {
+		return nil, nil, nil
+	}
+
+	toolset := make(map[string]tool.InvokableTool, len(resolved))
+	infos := make([]*schema.ToolInfo, 0, len(resolved))
+	for _, t := range resolved {
+		info, err := t.Info(l.ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		toolset[info.Name] = t
+		infos = append(infos, info)
+	}
+
+	return toolset, infos, nil
+}
+
+// runTool 执行模型请求的一次工具调用并推送调用和结果事件，返回交给模型的工具消息；
+// 执行失败时把错误作为结果交给模型，由模型决定如何回答
+func (l *ChatSendLogic) runTool(client chan<- *types.ChatSSEEvent, turn *chatTurn, toolset map[string]tool.InvokableTool,
+	call schema.ToolCall, state *streamState) *schema.Message {
+	info := types.ToolCallInfo{
+		ID:        call.ID,
+		Name:      call.Function.Name,
+		Arguments: call.Function.Arguments,
+	}
+	l.sendEvent(client, &types.ChatSSEEvent{
+		Type:           common.AI_SSE_Event_ToolCall,
+		ConversationID: turn.conversationId,
+		Tool:           &types.ToolCallInfo{ID: info.ID, Name: info.Name, Arguments: info.Arguments},
+	})
+
+	var result string
+	t, ok := toolset[call.Function.Name]
+	if !ok {
+		info.Error = fmt.Sprintf("工具 %s 不可用", call.Function.Name)
+	} else if output, err := t.InvokableRun(turn.ctx, call.Function.Arguments); err != nil {
+		info.Error = err.Error()
+	} else {
+		result = output
+		info.Result = output
+	}
+	if info.Error != "" {
+		l.Infof("Tool call failed - Tool: %s, Error: %s", info.Name, info.Error)
+		data, _ := json.Marshal(map[string]string{"error": info.Error})
+		result = string(data)
+	}
+
+	state.toolCalls = append(state.toolCalls, info)
+	l.sendEvent(client, &types.ChatSSEEvent{
+		Type:           common.AI_SSE_Event_ToolResult,
+		ConversationID: turn.conversationId,
+		Tool:           &info,
+	})
+	return schema.ToolMessage(result, call.ID, schema.WithToolName(call.Function.Name))
+}
@@ -43,6 +43,8 @@ func (l *CreateConversationLogic) CreateConversation(req *types.CreateConversati
 	// 转换请求为数据模型
 	converter := converter.NewChatConverter()
 	conversation := converter.FromCreateConversationRequest(req)
+	userId := int64(1)
+	conversation.UserID = &userId
 
 	// 创建对话
 	if err := chatRepo.CreateConversation(conversation); err != nil {
@@ -0,0 +1,125 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	characterModel "ai-roleplay/services/character/model"
+	"ai-roleplay/services/chat/api/internal/converter"
+	"ai-roleplay/services/chat/api/internal/group"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateGroupConversationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建多角色群聊
+func NewCreateGroupConversationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateGroupConversationLogic {
+	return &CreateGroupConversationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateGroupConversationLogic) CreateGroupConversation(req *types.CreateGroupRequest) (resp *types.GroupConversationResponse, err error) {
+	userId := int64(1)
+
+	// 1、校验发言策略和邀请的角色
+	policy := req.TurnPolicy
+	if policy == "" {
+		policy = group.PolicyMention
+	}
+	if !group.ValidPolicy(policy) {
+		return nil, fmt.Errorf("不支持的发言策略: %s", policy)
+	}
+	title := strings.TrimSpace(req.Title)
+	if utf8.RuneCountInString(title) > 200 {
+		return nil, fmt.Errorf("标题不能超过200个字符")
+	}
+
+	characterIds := make([]int64, 0, len(req.CharacterIDs))
+	seen := make(map[int64]bool, len(req.CharacterIDs))
+	for _, id := range req.CharacterIDs {
+		if id <= 0 {
+			return nil, fmt.Errorf("角色ID无效: %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			characterIds = append(characterIds, id)
+		}
+	}
+	if len(characterIds) < 2 {
+		return nil, fmt.Errorf("群聊至少需要邀请2个不同的角色")
+	}
+	if maxMembers := l.svcCtx.Config.Group.MaxMembers; len(characterIds) > maxMembers {
+		return nil, fmt.Errorf("群聊最多邀请%d个角色", maxMembers)
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	characters, err := chatRepo.GetCharactersByIDs(characterIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(characters) != len(characterIds) {
+		return nil, fmt.Errorf("部分角色不存在或已下架")
+	}
+
+	// 2、创建对话，对话绑定的角色为第一位参与角色
+	conversation := converter.NewChatConverter().FromCreateConversationRequest(&types.CreateConversationRequest{
+		CharacterID: characterIds[0],
+		Title:       title,
+	})
+	conversation.UserID = &userId
+	conversation.TurnPolicy = policy
+
+	participants, err := chatRepo.CreateGroupConversation(conversation, characterIds)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("Group conversation created - ConversationId: %d, Policy: %s, Characters: %v", conversation.ID, policy, characterIds)
+	return toGroupConversationResponse(conversation, participants, characters), nil
+}
+
+// toGroupConversationResponse 组装群聊信息，参与角色按轮流顺序排列，已下架的角色不返回
+func toGroupConversationResponse(conversation *model.Conversation, participants []model.ConversationParticipant,
+	characters []characterModel.Character) *types.GroupConversationResponse {
+	byId := make(map[int64]*characterModel.Character, len(characters))
+	for i := range characters {
+		byId[characters[i].ID] = &characters[i]
+	}
+
+	items := make([]types.ParticipantItem, 0, len(participants))
+	for _, participant := range participants {
+		character, ok := byId[participant.CharacterID]
+		if !ok {
+			continue
+		}
+		item := types.ParticipantItem{
+			CharacterID: character.ID,
+			Name:        character.Name,
+			Position:    int(participant.Position),
+		}
+		if character.Avatar != nil {
+			item.Avatar = *character.Avatar
+		}
+		items = append(items, item)
+	}
+	return &types.GroupConversationResponse{
+		ConversationID: conversation.ID,
+		Title:          conversation.Title,
+		TurnPolicy:     conversation.TurnPolicy,
+		Participants:   items,
+	}
+}
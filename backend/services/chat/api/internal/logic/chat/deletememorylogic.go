@@ -0,0 +1,48 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteMemoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除一条记忆
+func NewDeleteMemoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteMemoryLogic {
+	return &DeleteMemoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteMemoryLogic) DeleteMemory(req *types.MemoryRequest) (resp *types.BaseResponse, err error) {
+	userId := int64(1)
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	memory, err := chatRepo.GetMemoryByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if memory == nil || memory.UserID != userId {
+		return nil, fmt.Errorf("记忆不存在")
+	}
+
+	if err := chatRepo.DeleteMemory(req.ID, userId); err != nil {
+		return nil, err
+	}
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "删除成功",
+	}, nil
+}
@@ -0,0 +1,49 @@
+package chat
+
+import (
+	"context"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteMessageFeedbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 撤回对AI回复的评价
+func NewDeleteMessageFeedbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteMessageFeedbackLogic {
+	return &DeleteMessageFeedbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteMessageFeedbackLogic) DeleteMessageFeedback(req *types.MessageRequest) (resp *types.BaseResponse, err error) {
+	userId := int64(1)
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	record, err := chatRepo.GetMessageFeedback(req.ID, userId)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		if err := chatRepo.DeleteMessageFeedback(req.ID, userId); err != nil {
+			return nil, err
+		}
+		if record.CharacterID != nil {
+			refreshCharacterRating(l.svcCtx, *record.CharacterID)
+		}
+	}
+
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "撤回成功",
+	}, nil
+}
@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/memory"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type EditMessageLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 编辑用户消息并从该处重新生成回复（SSE），原消息及其后续对话作为旧分支保留
+func NewEditMessageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EditMessageLogic {
+	return &EditMessageLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *EditMessageLogic) Sse(req *types.EditMessageRequest, client chan<- *types.ChatSSEEvent) error {
+	userId := int64(1)
+	sendLogic := NewChatSendLogic(l.ctx, l.svcCtx)
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+
+	l.Infof("User %d editing message %d - ContentLength: %d", userId, req.ID, len(req.Content))
+
+	release, err := sendLogic.acquireStream(client, userId)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := sendLogic.checkQuota(client, userId); err != nil {
+		return err
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		err := fmt.Errorf("消息内容不能为空")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+	content, verdict, err := sendLogic.moderateInput(client, content)
+	if err != nil {
+		return err
+	}
+
+	// 1、校验要编辑的用户消息
+	message, err := chatRepo.GetMessageByID(req.ID)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取消息失败: %v", err))
+		return err
+	}
+	if message == nil || message.Type != common.AI_Role_User {
+		err := fmt.Errorf("只能编辑用户消息")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	conversation, err := chatRepo.GetConversationByID(message.ConversationID)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取对话失败: %v", err))
+		return err
+	}
+	if conversation == nil {
+		err := fmt.Errorf("对话不存在")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	// 新消息挂在原消息的父消息下，只允许编辑当前分支路径上的消息
+	path, err := chatRepo.GetMessagesAfter(conversation.ID, 0)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取对话历史失败: %v", err))
+		return err
+	}
+	onPath := false
+	for _, item := range path {
+		if item.ID == message.ID {
+			onPath = true
+			break
+		}
+	}
+	if !onPath {
+		err := fmt.Errorf("请先切换到该消息所在的分支")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	// 2、新分支会替换摘要已覆盖的内容时，先丢弃旧摘要
+	var parentId int64
+	if message.ParentID != nil {
+		parentId = *message.ParentID
+	}
+	if err := memory.NewSummarizer(l.ctx, l.svcCtx).Invalidate(conversation.ID, parentId); err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("更新对话摘要失败: %v", err))
+		return err
+	}
+
+	// 3、保存编辑后的消息作为原消息的兄弟分支
+	userMessage := &model.Message{
+		ConversationID: conversation.ID,
+		ParentID:       message.ParentID,
+		Content:        content,
+		Type:           common.AI_Role_User,
+	}
+	if err := setInputModeration(userMessage, verdict); err != nil {
+		l.Errorf("Set message metadata failed: %v", err)
+	}
+	if _, err := chatRepo.AddBranchMessage(userMessage); err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("保存用户消息失败: %v", err))
+		return err
+	}
+
+	// 4、基于编辑后的消息生成回复
+	turn, err := sendLogic.prepareTurn(client, conversation.ID, userId, userMessage, req.Model, conversation.CharacterID, 0)
+	if err != nil {
+		return err
+	}
+	return sendLogic.streamCallModelWithChannel(client, turn)
+}
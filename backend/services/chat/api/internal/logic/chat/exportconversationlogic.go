@@ -1,11 +1,17 @@
 package chat
 
 import (
-	"ai-roleplay/services/chat/api/internal/converter"
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"ai-roleplay/services/character/model"
+	"ai-roleplay/services/chat/api/internal/export"
 	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
-	"context"
+	chatModel "ai-roleplay/services/chat/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -16,6 +22,7 @@ type ExportConversationLogic struct {
 	svcCtx *svc.ServiceContext
 }
 
+// 导出对话记录，按 format 返回对应格式的文件下载
 func NewExportConversationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportConversationLogic {
 	return &ExportConversationLogic{
 		Logger: logx.WithContext(ctx),
@@ -24,38 +31,76 @@ func NewExportConversationLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 	}
 }
 
-func (l *ExportConversationLogic) ExportConversation(req *types.ConversationRequest) (resp *types.ExportResponse, err error) {
-	// 参数验证
+func (l *ExportConversationLogic) ExportConversation(req *types.ExportRequest) (*export.File, error) {
 	if req.ID <= 0 {
-		return &types.ExportResponse{
-			Code: 400,
-			Msg:  "对话ID无效",
-		}, nil
+		return nil, fmt.Errorf("对话ID无效")
+	}
+	exporter, err := l.svcCtx.Exporters.Get(req.Format)
+	if err != nil {
+		return nil, err
 	}
 
-	// 创建repo实例
+	// 1、获取对话和全部消息
 	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
-
-	// 获取对话信息和消息记录
 	conversation, messages, err := chatRepo.ExportConversation(req.ID)
 	if err != nil {
-		l.Logger.Error("ExportConversation failed: ", err)
-		return &types.ExportResponse{
-			Code: 500,
-			Msg:  "获取对话数据失败",
-		}, nil
+		return nil, fmt.Errorf("获取对话数据失败")
 	}
-
 	if conversation == nil {
-		return &types.ExportResponse{
-			Code: 404,
-			Msg:  "对话不存在",
-		}, nil
+		return nil, fmt.Errorf("对话不存在")
+	}
+	path, err := chatRepo.GetMessagesAfter(conversation.ID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取对话数据失败")
 	}
 
-	// 使用转换器生成导出内容
-	converter := converter.NewChatConverter()
-	resp = converter.BuildExportResponse(conversation, messages)
+	doc := &export.Document{
+		Conversation: conversation,
+		Messages:     messages,
+		Path:         path,
+		Characters:   map[int64]*model.Character{},
+		AudioFiles:   map[int64]*chatModel.AudioFile{},
+		ExportedAt:   time.Now(),
+	}
+
+	// 2、加载对话和发言涉及的角色、消息引用的语音文件
+	characterIds := []int64{conversation.CharacterID}
+	var audioIds []int64
+	for _, message := range messages {
+		if message.CharacterID != nil {
+			characterIds = append(characterIds, *message.CharacterID)
+		}
+		if message.AudioID != nil {
+			audioIds = append(audioIds, *message.AudioID)
+		}
+	}
+	characters, err := chatRepo.GetCharactersByIDs(characterIds)
+	if err != nil {
+		return nil, fmt.Errorf("获取角色信息失败")
+	}
+	for i := range characters {
+		doc.Characters[characters[i].ID] = &characters[i]
+	}
+	if len(audioIds) > 0 {
+		audioFiles, err := chatRepo.GetAudioFiles(audioIds)
+		if err != nil {
+			return nil, fmt.Errorf("获取语音文件失败")
+		}
+		for i := range audioFiles {
+			doc.AudioFiles[audioFiles[i].ID] = &audioFiles[i]
+		}
+	}
+
+	// 3、按格式生成文件
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, doc); err != nil {
+		l.Logger.Error("ExportConversation failed: ", err)
+		return nil, fmt.Errorf("生成导出文件失败")
+	}
 
-	return resp, nil
+	return &export.File{
+		Filename:    export.Filename(doc, exporter),
+		ContentType: exporter.ContentType(),
+		Data:        buf.Bytes(),
+	}, nil
 }
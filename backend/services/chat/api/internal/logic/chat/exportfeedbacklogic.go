@@ -0,0 +1,111 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/feedback"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// feedbackExportMaxPageSize 导出反馈时每页最多的条数
+const feedbackExportMaxPageSize = 1000
+
+type ExportFeedbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 导出消息反馈及对应的问题和回复，用于调整提示词；普通用户只能导出自己的反馈，管理员可导出全部
+func NewExportFeedbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportFeedbackLogic {
+	return &ExportFeedbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportFeedbackLogic) ExportFeedback(req *types.FeedbackExportRequest) (resp *types.FeedbackExportResponse, err error) {
+	var vote int8
+	if req.Vote != "" {
+		value, ok := feedback.VoteValue(req.Vote)
+		if !ok {
+			return nil, fmt.Errorf("评价无效: %s", req.Vote)
+		}
+		vote = value
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	req.PageSize = min(max(req.PageSize, 1), feedbackExportMaxPageSize)
+
+	start, end, err := parseUsageRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 反馈包含对话内容，非管理员只能导出自己的反馈
+	userId := int64(1)
+	scopeUserId := userId
+	if l.svcCtx.Config.Admin.IsAdmin(userId) {
+		scopeUserId = 0
+	}
+
+	rows, total, err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).GetFeedbackExport(scopeUserId, vote, req.CharacterID, start, end, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.FeedbackExportItem, 0, len(rows))
+	for i := range rows {
+		list = append(list, toFeedbackExportItem(&rows[i]))
+	}
+	return &types.FeedbackExportResponse{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+func toFeedbackExportItem(row *repo.FeedbackExportRow) types.FeedbackExportItem {
+	item := types.FeedbackExportItem{
+		MessageID:      row.MessageID,
+		ConversationID: row.ConversationID,
+		Vote:           feedback.VoteName(row.Vote),
+		Reasons:        []string{},
+		Comment:        row.Comment,
+		Reply:          row.Reply,
+		UpdatedAt:      row.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if row.CharacterID != nil {
+		item.CharacterID = *row.CharacterID
+	}
+	if row.CharacterName != nil {
+		item.CharacterName = *row.CharacterName
+	}
+	if row.Question != nil {
+		item.Question = *row.Question
+	}
+	if row.Reasons != nil {
+		json.Unmarshal([]byte(*row.Reasons), &item.Reasons)
+	}
+	// 回复的元数据中记录了生成它的提供方和模型
+	if row.Metadata != nil {
+		var metadata struct {
+			Provider string `json:"provider"`
+			Model    string `json:"model"`
+		}
+		if json.Unmarshal([]byte(*row.Metadata), &metadata) == nil {
+			item.Provider = metadata.Provider
+			item.Model = metadata.Model
+		}
+	}
+	return item
+}
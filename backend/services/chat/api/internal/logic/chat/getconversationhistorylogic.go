@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -33,8 +35,14 @@ func (l *GetConversationHistoryLogic) GetConversationHistory(req *types.GetConve
 	// 创建repo实例
 	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
 
-	// 获取对话历史
-	conversations, total, err := chatRepo.GetConversationsByUserID(req.UserID, req.Page, req.PageSize, int64(req.CharacterID))
+	// 获取对话历史，有关键词时按标题和消息内容检索
+	var conversations []model.Conversation
+	var total int64
+	if strings.TrimSpace(req.Keyword) != "" {
+		conversations, total, err = chatRepo.GetConversationHistory(req)
+	} else {
+		conversations, total, err = chatRepo.GetConversationsByUserID(req.UserID, req.Page, req.PageSize, int64(req.CharacterID))
+	}
 	if err != nil {
 		l.Logger.Error("GetConversationHistory failed: ", err)
 		return nil, err
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetConversationSummaryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取对话摘要
+func NewGetConversationSummaryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetConversationSummaryLogic {
+	return &GetConversationSummaryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetConversationSummaryLogic) GetConversationSummary(req *types.ConversationRequest) (resp *types.SummaryResponse, err error) {
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	conversation, err := chatRepo.GetConversationByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, fmt.Errorf("对话不存在")
+	}
+
+	summary, err := chatRepo.GetConversationSummary(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toSummaryResponse(req.ID, summary), nil
+}
+
+func toSummaryResponse(conversationId int64, summary *model.ConversationSummary) *types.SummaryResponse {
+	resp := &types.SummaryResponse{ConversationID: conversationId}
+	if summary != nil {
+		resp.Summary = summary.Summary
+		resp.CoveredMessageID = summary.CoveredMessageID
+		resp.MessageCount = int(summary.MessageCount)
+		resp.UpdatedAt = summary.UpdatedAt.Format("2006-01-02 15:04:05")
+	}
+	return resp
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetMemoriesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取角色记住的关于我的信息
+func NewGetMemoriesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetMemoriesLogic {
+	return &GetMemoriesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetMemoriesLogic) GetMemories(req *types.MemoryListRequest) (resp *types.MemoryListResponse, err error) {
+	userId := int64(1)
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	memories, err := chatRepo.GetMemories(userId, req.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.MemoryItem, 0, len(memories))
+	for i := range memories {
+		list = append(list, *toMemoryItem(&memories[i]))
+	}
+	return &types.MemoryListResponse{
+		List:  list,
+		Total: len(list),
+	}, nil
+}
+
+func toMemoryItem(memory *model.UserCharacterMemory) *types.MemoryItem {
+	return &types.MemoryItem{
+		ID:          memory.ID,
+		CharacterID: memory.CharacterID,
+		Content:     memory.Content,
+		Importance:  int(memory.Importance),
+		UpdatedAt:   memory.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
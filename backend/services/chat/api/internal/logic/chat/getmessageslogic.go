@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"ai-roleplay/services/chat/api/internal/converter"
+	"ai-roleplay/services/chat/api/internal/history"
 	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
@@ -41,8 +42,18 @@ func (l *GetMessagesLogic) GetMessages(req *types.MessageListRequest) (resp *typ
 	// 创建repo实例
 	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
 
-	// 获取消息列表
-	messages, total, err := chatRepo.GetMessages(req)
+	// 计算分页信息
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	// 获取消息列表，只返回当前选中的分支路径，分页在数据库中完成
+	path, total, err := chatRepo.GetActivePathPage(req.ConversationID, page, pageSize)
 	if err != nil {
 		l.Logger.Error("GetMessages failed: ", err)
 		return &types.MessageListResponse{
@@ -50,23 +61,24 @@ func (l *GetMessagesLogic) GetMessages(req *types.MessageListRequest) (resp *typ
 			Msg:  "获取消息列表失败",
 		}, nil
 	}
+	hasMore := int64(page*pageSize) < total
 
-	l.Logger.Infof("查询到 %d 条消息，总数: %d", len(messages), total)
+	// 只加载本页消息的兄弟分支，用于分支切换
+	parentIDs, hasRoot := history.ParentIDs(path)
+	siblings, err := chatRepo.GetSiblingMessages(req.ConversationID, parentIDs, hasRoot)
+	if err != nil {
+		l.Logger.Error("GetMessages siblings failed: ", err)
+		return &types.MessageListResponse{
+			Code: 500,
+			Msg:  "获取消息列表失败",
+		}, nil
+	}
+
+	l.Logger.Infof("查询到 %d 条消息，总数: %d", len(path), total)
 
 	// 转换数据格式
 	converter := converter.NewChatConverter()
-	messageList := converter.ToMessageList(messages)
-
-	// 计算分页信息
-	page := req.Page
-	if page <= 0 {
-		page = 1
-	}
-	pageSize := req.PageSize
-	if pageSize <= 0 {
-		pageSize = 50
-	}
-	hasMore := int64(page*pageSize) < total
+	messageList := converter.ToBranchMessageList(path, history.Siblings(siblings))
 
 	return &types.MessageListResponse{
 		Code:     0,
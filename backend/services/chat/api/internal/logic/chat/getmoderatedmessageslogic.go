@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/moderation"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetModeratedMessagesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 列出被审核标记、遮蔽或拦截的消息，普通用户只能查看自己对话中的消息，管理员可查看全部及原文
+func NewGetModeratedMessagesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetModeratedMessagesLogic {
+	return &GetModeratedMessagesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetModeratedMessagesLogic) GetModeratedMessages(req *types.ModerationListRequest) (resp *types.ModerationListResponse, err error) {
+	if req.Stage != "" && req.Stage != moderation.StageInput && req.Stage != moderation.StageOutput {
+		return nil, fmt.Errorf("审核阶段无效: %s", req.Stage)
+	}
+	if req.Action != "" && !moderation.ValidAction(req.Action) {
+		return nil, fmt.Errorf("处理方式无效: %s", req.Action)
+	}
+
+	// 非管理员只能查看自己对话中的消息，且不返回被遮蔽或拦截前的原文
+	userId := int64(1)
+	isAdmin := l.svcCtx.Config.Admin.IsAdmin(userId)
+	scopeUserId := userId
+	if isAdmin {
+		scopeUserId = 0
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	messages, total, err := chatRepo.GetModeratedMessages(scopeUserId, req.Stage, req.Action, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.ModerationItem, 0, len(messages))
+	for i := range messages {
+		item, err := toModerationItem(&messages[i], isAdmin)
+		if err != nil {
+			l.Errorf("Parse moderation verdict failed - MessageId: %d, Error: %v", messages[i].ID, err)
+			continue
+		}
+		list = append(list, *item)
+	}
+	return &types.ModerationListResponse{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// toModerationItem 转换审核记录，withOriginal 为 false 时不返回原文
+func toModerationItem(message *model.Message, withOriginal bool) (*types.ModerationItem, error) {
+	var metadata struct {
+		Moderation moderation.Verdict `json:"moderation"`
+	}
+	if message.Metadata != nil {
+		if err := json.Unmarshal([]byte(*message.Metadata), &metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	verdict := metadata.Moderation
+	if withOriginal && message.OriginalContent != nil {
+		verdict.Original = *message.OriginalContent
+	}
+	findings := make([]types.ModerationFinding, 0, len(verdict.Findings))
+	for _, finding := range verdict.Findings {
+		findings = append(findings, types.ModerationFinding{
+			Checker: finding.Checker,
+			Action:  finding.Action,
+			Reason:  finding.Reason,
+			Match:   finding.Match,
+		})
+	}
+	return &types.ModerationItem{
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		Type:           message.Type,
+		Content:        message.Content,
+		Original:       verdict.Original,
+		Stage:          verdict.Stage,
+		Action:         verdict.Action,
+		Findings:       findings,
+		CreatedAt:      message.CreatedAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
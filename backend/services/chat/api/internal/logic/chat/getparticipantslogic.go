@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetParticipantsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取群聊的参与角色
+func NewGetParticipantsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetParticipantsLogic {
+	return &GetParticipantsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetParticipantsLogic) GetParticipants(req *types.ConversationRequest) (resp *types.GroupConversationResponse, err error) {
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	conversation, err := chatRepo.GetConversationByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, fmt.Errorf("对话不存在")
+	}
+	if !conversation.IsGroup() {
+		return nil, fmt.Errorf("该对话不是群聊")
+	}
+
+	participants, err := chatRepo.GetParticipants(conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(participants))
+	for _, participant := range participants {
+		ids = append(ids, participant.CharacterID)
+	}
+	characters, err := chatRepo.GetCharactersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGroupConversationResponse(conversation, participants, characters), nil
+}
@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetPromptPreviewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 预览角色编译后的系统提示词
+func NewGetPromptPreviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPromptPreviewLogic {
+	return &GetPromptPreviewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPromptPreviewLogic) GetPromptPreview(req *types.PromptPreviewRequest) (resp *types.PromptPreviewResponse, err error) {
+	if req.CharacterID <= 0 {
+		return nil, fmt.Errorf("角色ID无效")
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	character, err := chatRepo.GetCharacterByID(req.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("角色不存在")
+	}
+
+	characterPrompt := prompt.BuildCharacterPrompt(character)
+	return &types.PromptPreviewResponse{
+		CharacterID:   characterPrompt.CharacterID,
+		CharacterName: characterPrompt.CharacterName,
+		Policy:        characterPrompt.Policy,
+		Persona:       characterPrompt.Persona,
+		SystemPrompt:  characterPrompt.String(),
+	}, nil
+}
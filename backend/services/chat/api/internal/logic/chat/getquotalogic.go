@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/quota"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetQuotaLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取当前套餐的每日和每月token额度及用量
+func NewGetQuotaLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetQuotaLogic {
+	return &GetQuotaLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetQuotaLogic) GetQuota() (resp *types.QuotaResponse, err error) {
+	userId := int64(1)
+
+	if !l.svcCtx.Config.Quota.Enable {
+		return nil, fmt.Errorf("未启用套餐额度")
+	}
+	usage, err := l.svcCtx.Quota.Get(l.ctx, userId)
+	if err != nil {
+		l.Errorf("Get quota failed: %v", err)
+		return nil, fmt.Errorf("获取额度失败")
+	}
+	return toQuotaResponse(usage), nil
+}
+
+func toQuotaResponse(usage *quota.Usage) *types.QuotaResponse {
+	return &types.QuotaResponse{
+		Plan:    usage.Plan,
+		Daily:   toQuotaItem(usage.Daily),
+		Monthly: toQuotaItem(usage.Monthly),
+	}
+}
+
+func toQuotaItem(period quota.PeriodUsage) types.QuotaItem {
+	return types.QuotaItem{
+		Used:      period.Used,
+		Limit:     period.Limit,
+		Remaining: period.Remaining(),
+		ResetAt:   period.ResetAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	usageDateLayout  = "2006-01-02"
+	usageDefaultDays = 30
+)
+
+// usagePeriodFormats 统计周期对应的 MySQL DATE_FORMAT 格式
+var usagePeriodFormats = map[string]string{
+	"day":   "%Y-%m-%d",
+	"week":  "%x-W%v",
+	"month": "%Y-%m",
+}
+
+type GetUsageLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取AI回复的token用量和耗时统计
+func NewGetUsageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUsageLogic {
+	return &GetUsageLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetUsageLogic) GetUsage(req *types.UsageRequest) (resp *types.UsageResponse, err error) {
+	userId := int64(1)
+
+	period := req.Period
+	if period == "" {
+		period = "day"
+	}
+	periodFormat, ok := usagePeriodFormats[period]
+	if !ok {
+		return nil, fmt.Errorf("不支持的统计周期: %s", period)
+	}
+
+	start, end, err := parseUsageRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	if req.ConversationID > 0 {
+		conversation, err := chatRepo.GetConversationByID(req.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if conversation == nil {
+			return nil, fmt.Errorf("对话不存在")
+		}
+	}
+
+	stats, err := chatRepo.GetUsageStats(userId, req.ConversationID, start, end, periodFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.UsageResponse{
+		Period: period,
+		List:   make([]types.UsageItem, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		resp.List = append(resp.List, toUsageItem(stat))
+		resp.Total.MessageCount += stat.MessageCount
+		resp.Total.TotalTokens += stat.TotalTokens
+		resp.Total.TotalProcessingTime += stat.TotalProcessingTime
+	}
+	if resp.Total.MessageCount > 0 {
+		resp.Total.AvgProcessingTime = float64(resp.Total.TotalProcessingTime) / float64(resp.Total.MessageCount)
+	}
+
+	// 附带当前套餐的额度，读取失败不影响统计结果
+	if l.svcCtx.Config.Quota.Enable {
+		if usage, err := l.svcCtx.Quota.Get(l.ctx, userId); err != nil {
+			l.Errorf("Get quota failed: %v", err)
+		} else {
+			resp.Quota = toQuotaResponse(usage)
+		}
+	}
+
+	return resp, nil
+}
+
+// parseUsageRange 解析统计的日期范围，返回 [start, end) ，结束日期包含当天
+func parseUsageRange(startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if endDate != "" {
+		t, err := time.ParseInLocation(usageDateLayout, endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("结束日期格式错误: %s", endDate)
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+
+	start := end.AddDate(0, 0, -usageDefaultDays)
+	if startDate != "" {
+		t, err := time.ParseInLocation(usageDateLayout, startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("开始日期格式错误: %s", startDate)
+		}
+		start = t
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	return start, end, nil
+}
+
+func toUsageItem(stat repo.UsageStat) types.UsageItem {
+	item := types.UsageItem{
+		Period:              stat.Period,
+		MessageCount:        stat.MessageCount,
+		TotalTokens:         stat.TotalTokens,
+		TotalProcessingTime: stat.TotalProcessingTime,
+	}
+	if stat.MessageCount > 0 {
+		item.AvgProcessingTime = float64(stat.TotalProcessingTime) / float64(stat.MessageCount)
+	}
+	return item
+}
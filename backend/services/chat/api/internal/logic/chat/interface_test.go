@@ -69,8 +69,9 @@ func TestDeleteConversationLogic(t *testing.T) {
 
 func TestExportConversationLogic(t *testing.T) {
 	exportConversationLogic = NewExportConversationLogic(ctx, svcCtx)
-	resp, err := exportConversationLogic.ExportConversation(&types.ConversationRequest{
-		ID: 1,
+	resp, err := exportConversationLogic.ExportConversation(&types.ExportRequest{
+		ID:     1,
+		Format: "txt",
 	})
 	if err != nil {
 		t.Fatalf("ExportConversation failed: %v", err)
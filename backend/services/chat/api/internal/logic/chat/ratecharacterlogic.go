@@ -0,0 +1,60 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/feedback"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RateCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 给角色打1-5星评分，重复提交时覆盖
+func NewRateCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RateCharacterLogic {
+	return &RateCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RateCharacterLogic) RateCharacter(req *types.CharacterRatingRequest) (resp *types.BaseResponse, err error) {
+	userId := int64(1)
+
+	if !feedback.ValidScore(req.Score) {
+		return nil, fmt.Errorf("评分需在1到5之间")
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	character, err := chatRepo.GetCharacterByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("角色不存在")
+	}
+
+	if err := chatRepo.SaveCharacterRating(&model.CharacterRating{
+		CharacterID: character.ID,
+		UserID:      userId,
+		Score:       int32(req.Score),
+	}); err != nil {
+		return nil, fmt.Errorf("保存评分失败")
+	}
+	refreshCharacterRating(l.svcCtx, character.ID)
+
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "评分成功",
+	}, nil
+}
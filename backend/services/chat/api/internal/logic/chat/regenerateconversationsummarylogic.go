@@ -0,0 +1,46 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/memory"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegenerateConversationSummaryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重新生成对话摘要
+func NewRegenerateConversationSummaryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegenerateConversationSummaryLogic {
+	return &RegenerateConversationSummaryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RegenerateConversationSummaryLogic) RegenerateConversationSummary(req *types.ConversationRequest) (resp *types.SummaryResponse, err error) {
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	conversation, err := chatRepo.GetConversationByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, fmt.Errorf("对话不存在")
+	}
+
+	summary, err := memory.NewSummarizer(l.ctx, l.svcCtx).Regenerate(req.ID)
+	if err != nil {
+		l.Errorf("Regenerate summary failed - ConversationId: %d, Error: %v", req.ID, err)
+		return nil, err
+	}
+	return toSummaryResponse(req.ID, summary), nil
+}
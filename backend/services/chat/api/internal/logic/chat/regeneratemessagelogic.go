@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/memory"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegenerateMessageLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重新生成AI回复（SSE），新回复作为原回复的兄弟分支保存并被选中
+func NewRegenerateMessageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegenerateMessageLogic {
+	return &RegenerateMessageLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RegenerateMessageLogic) Sse(req *types.RegenerateRequest, client chan<- *types.ChatSSEEvent) error {
+	userId := int64(1)
+	sendLogic := NewChatSendLogic(l.ctx, l.svcCtx)
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+
+	l.Infof("User %d regenerating message %d", userId, req.ID)
+
+	release, err := sendLogic.acquireStream(client, userId)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := sendLogic.checkQuota(client, userId); err != nil {
+		return err
+	}
+
+	// 1、校验要重新生成的回复
+	message, err := chatRepo.GetMessageByID(req.ID)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取消息失败: %v", err))
+		return err
+	}
+	if message == nil || message.Type == common.AI_Role_User || message.ParentID == nil {
+		err := fmt.Errorf("只能重新生成AI回复")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	conversation, err := chatRepo.GetConversationByID(message.ConversationID)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取对话失败: %v", err))
+		return err
+	}
+	if conversation == nil {
+		err := fmt.Errorf("对话不存在")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	// 历史按当前分支路径构建，只允许重新生成路径上的回复
+	path, err := chatRepo.GetMessagesAfter(conversation.ID, 0)
+	if err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("获取对话历史失败: %v", err))
+		return err
+	}
+	var userMessage *model.Message
+	for i := 1; i < len(path); i++ {
+		if path[i].ID == message.ID {
+			userMessage = &path[i-1]
+			break
+		}
+	}
+	if userMessage == nil {
+		err := fmt.Errorf("请先切换到该回复所在的分支")
+		sendLogic.sendError(client, err.Error())
+		return err
+	}
+
+	// 2、新分支会替换摘要已覆盖的内容时，先丢弃旧摘要
+	if err := memory.NewSummarizer(l.ctx, l.svcCtx).Invalidate(conversation.ID, userMessage.ID); err != nil {
+		sendLogic.sendError(client, fmt.Sprintf("更新对话摘要失败: %v", err))
+		return err
+	}
+
+	// 3、基于原用户消息重新生成，群聊中仍由原回复的角色发言
+	var speakerId int64
+	if message.CharacterID != nil {
+		speakerId = *message.CharacterID
+	}
+	turn, err := sendLogic.prepareTurn(client, conversation.ID, userId, userMessage, req.Model, conversation.CharacterID, speakerId)
+	if err != nil {
+		return err
+	}
+	return sendLogic.streamCallModelWithChannel(client, turn)
+}
@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/feedback"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+type SaveMessageFeedbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 评价一条AI回复（赞或踩），可附带原因标签和补充说明，重复提交时覆盖
+func NewSaveMessageFeedbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveMessageFeedbackLogic {
+	return &SaveMessageFeedbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SaveMessageFeedbackLogic) SaveMessageFeedback(req *types.FeedbackRequest) (resp *types.FeedbackResponse, err error) {
+	userId := int64(1)
+
+	vote, reasons, err := feedback.Normalize(req.Vote, req.Reasons, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	message, characterId, err := getFeedbackMessage(l.ctx, l.svcCtx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reasonsJSON, err := json.Marshal(reasons)
+	if err != nil {
+		return nil, err
+	}
+	reasonsStr := string(reasonsJSON)
+	record := &model.MessageFeedback{
+		MessageID:      message.ID,
+		UserID:         userId,
+		ConversationID: message.ConversationID,
+		Vote:           vote,
+		Reasons:        &reasonsStr,
+		Comment:        req.Comment,
+	}
+	if characterId > 0 {
+		record.CharacterID = &characterId
+	}
+	if err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).SaveMessageFeedback(record); err != nil {
+		return nil, fmt.Errorf("保存评价失败")
+	}
+	refreshCharacterRating(l.svcCtx, characterId)
+
+	return &types.FeedbackResponse{
+		MessageID: message.ID,
+		Vote:      req.Vote,
+		Reasons:   reasons,
+		Comment:   req.Comment,
+	}, nil
+}
+
+// getFeedbackMessage 获取可评价的AI消息和回复它的角色，单角色对话的旧消息没有记录角色时取对话的角色
+func getFeedbackMessage(ctx context.Context, svcCtx *svc.ServiceContext, messageId int64) (*model.Message, int64, error) {
+	chatRepo := repo.NewChatServiceRepo(ctx, svcCtx)
+	message, err := chatRepo.GetMessageByID(messageId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if message == nil {
+		return nil, 0, fmt.Errorf("消息不存在")
+	}
+	if message.Type == common.AI_Role_User {
+		return nil, 0, fmt.Errorf("只能评价AI回复")
+	}
+	if message.CharacterID != nil {
+		return message, *message.CharacterID, nil
+	}
+
+	conversation, err := chatRepo.GetConversationByID(message.ConversationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if conversation == nil {
+		return nil, 0, fmt.Errorf("对话不存在")
+	}
+	return message, conversation.CharacterID, nil
+}
+
+// refreshCharacterRating 异步重新汇总角色的评分
+func refreshCharacterRating(svcCtx *svc.ServiceContext, characterId int64) {
+	if characterId <= 0 {
+		return
+	}
+	threading.GoSafe(func() {
+		if err := repo.NewChatServiceRepo(context.Background(), svcCtx).RefreshCharacterRating(characterId); err != nil {
+			logx.Errorf("Refresh character rating failed - CharacterId: %d, Error: %v", characterId, err)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package chat
 import (
 	"context"
 
+	"ai-roleplay/services/chat/api/internal/converter"
+	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
 
@@ -25,7 +27,19 @@ func NewSearchConversationsLogic(ctx context.Context, svcCtx *svc.ServiceContext
 }
 
 func (l *SearchConversationsLogic) SearchConversations(req *types.SearchConversationRequest) (resp *types.ConversationListResponse, err error) {
-	// todo: add your logic here and delete this line
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	// 标题或消息内容命中关键词的对话
+	conversations, total, err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).SearchConversations(req)
+	if err != nil {
+		l.Logger.Error("SearchConversations failed: ", err)
+		return nil, err
+	}
 
-	return
+	return converter.NewChatConverter().BuildConversationListResponse(conversations, total, req.Page, req.PageSize), nil
 }
@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/search"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	messageSearchMaxPageSize = 100
+	snippetRadius            = 30 // 命中前后保留的字符数
+	maxSnippets              = 3  // 每条消息最多返回的片段数
+)
+
+type SearchMessagesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 全文检索消息内容，可按角色和日期筛选，返回带高亮的片段和消息ID
+func NewSearchMessagesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SearchMessagesLogic {
+	return &SearchMessagesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SearchMessagesLogic) SearchMessages(req *types.MessageSearchRequest) (resp *types.MessageSearchResponse, err error) {
+	userId := int64(1)
+
+	terms := search.Terms(req.Keyword)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("关键词不能为空")
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	req.PageSize = min(max(req.PageSize, 1), messageSearchMaxPageSize)
+
+	start, end, err := parseSearchRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, total, err := repo.NewChatServiceRepo(l.ctx, l.svcCtx).SearchMessages(userId, terms, req.CharacterID, start, end, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.MessageSearchItem, 0, len(rows))
+	for _, row := range rows {
+		item := types.MessageSearchItem{
+			MessageID:         row.MessageID,
+			ConversationID:    row.ConversationID,
+			ConversationTitle: row.ConversationTitle,
+			CharacterID:       row.CharacterID,
+			Type:              row.Type,
+			IsActive:          row.IsActive,
+			Snippets:          search.Highlight(row.Content, terms, snippetRadius, maxSnippets),
+			CreatedAt:         row.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if row.CharacterName != nil {
+			item.CharacterName = *row.CharacterName
+		}
+		list = append(list, item)
+	}
+	return &types.MessageSearchResponse{
+		List:     list,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// parseSearchRange 解析检索的日期范围 [start, end)，未指定的一端返回零值表示不限
+func parseSearchRange(startDate, endDate string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if startDate != "" {
+		t, err := time.ParseInLocation(usageDateLayout, startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("开始日期格式错误: %s", startDate)
+		}
+		start = t
+	}
+	if endDate != "" {
+		t, err := time.ParseInLocation(usageDateLayout, endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("结束日期格式错误: %s", endDate)
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	return start, end, nil
+}
@@ -1,6 +1,7 @@
 package chat
 
 import (
+	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/chat/api/internal/converter"
 	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
@@ -40,7 +41,7 @@ func (l *SendMessageLogic) SendMessage(req *types.SendMessageRequest) (resp *typ
 		}, nil
 	}
 
-	if req.Type != "user" && req.Type != "ai" {
+	if req.Type != common.Message_Type_User && req.Type != common.Message_Type_AI {
 		return &types.SendMessageResponse{
 			Code: 400,
 			Msg:  "消息类型无效",
@@ -71,8 +72,18 @@ func (l *SendMessageLogic) SendMessage(req *types.SendMessageRequest) (resp *typ
 	converter := converter.NewChatConverter()
 	message := converter.FromSendMessageRequest(req)
 
-	// 保存消息
-	if err := chatRepo.SendMessage(message); err != nil {
+	// 保存消息，接在当前分支的最后一条消息之后
+	last, err := chatRepo.GetLastPathMessage(req.ConversationID)
+	if err != nil {
+		return &types.SendMessageResponse{
+			Code: 500,
+			Msg:  "获取消息列表失败",
+		}, nil
+	}
+	if last != nil {
+		message.ParentID = &last.ID
+	}
+	if _, err := chatRepo.AddBranchMessage(message); err != nil {
 		l.Logger.Error("SendMessage failed: ", err)
 		return &types.SendMessageResponse{
 			Code: 500,
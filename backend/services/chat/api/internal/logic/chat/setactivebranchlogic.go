@@ -0,0 +1,65 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/services/chat/api/internal/memory"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SetActiveBranchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 选中消息所在的分支
+func NewSetActiveBranchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SetActiveBranchLogic {
+	return &SetActiveBranchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SetActiveBranchLogic) SetActiveBranch(req *types.MessageRequest) (resp *types.BaseResponse, err error) {
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	message, err := chatRepo.GetMessageByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, fmt.Errorf("消息不存在")
+	}
+
+	conversation, err := chatRepo.GetConversationByID(message.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, fmt.Errorf("对话不存在")
+	}
+
+	if message.IsActive != 1 {
+		var parentId int64
+		if message.ParentID != nil {
+			parentId = *message.ParentID
+		}
+		if err := memory.NewSummarizer(l.ctx, l.svcCtx).Invalidate(conversation.ID, parentId); err != nil {
+			return nil, err
+		}
+		if err := chatRepo.SetActiveBranch(message); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "切换成功",
+	}, nil
+}
@@ -2,7 +2,11 @@ package chat
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
+	"ai-roleplay/services/chat/api/internal/repo"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
 
@@ -25,7 +29,29 @@ func NewUpdateConversationTitleLogic(ctx context.Context, svcCtx *svc.ServiceCon
 }
 
 func (l *UpdateConversationTitleLogic) UpdateConversationTitle(req *types.UpdateTitleRequest) (resp *types.BaseResponse, err error) {
-	// todo: add your logic here and delete this line
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("标题不能为空")
+	}
+	if utf8.RuneCountInString(title) > 200 {
+		return nil, fmt.Errorf("标题不能超过200个字符")
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	conversation, err := chatRepo.GetConversationByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, fmt.Errorf("对话不存在")
+	}
+
+	if err := chatRepo.UpdateConversationTitle(req.ID, title); err != nil {
+		return nil, err
+	}
 
-	return
+	return &types.BaseResponse{
+		Code: 0,
+		Msg:  "更新成功",
+	}, nil
 }
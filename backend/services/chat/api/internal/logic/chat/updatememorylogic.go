@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateMemoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 修改一条记忆
+func NewUpdateMemoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateMemoryLogic {
+	return &UpdateMemoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateMemoryLogic) UpdateMemory(req *types.UpdateMemoryRequest) (resp *types.MemoryItem, err error) {
+	userId := int64(1)
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("记忆内容不能为空")
+	}
+	if utf8.RuneCountInString(content) > 500 {
+		return nil, fmt.Errorf("记忆内容不能超过500字")
+	}
+
+	chatRepo := repo.NewChatServiceRepo(l.ctx, l.svcCtx)
+	memory, err := chatRepo.GetMemoryByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if memory == nil || memory.UserID != userId {
+		return nil, fmt.Errorf("记忆不存在")
+	}
+
+	importance := memory.Importance
+	if req.Importance >= 1 && req.Importance <= 5 {
+		importance = int32(req.Importance)
+	}
+	if err := chatRepo.UpdateMemory(req.ID, userId, content, importance); err != nil {
+		return nil, err
+	}
+
+	memory, err = chatRepo.GetMemoryByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toMemoryItem(memory), nil
+}
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 单条记忆内容和主题的最大字符数，与表字段长度一致
+const (
+	maxMemoryLength  = 500
+	maxFactKeyLength = 50
+)
+
+// Store 按（用户，角色）维护跨对话的长期记忆
+type Store struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewStore(ctx context.Context, svcCtx *svc.ServiceContext) *Store {
+	return &Store{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Extract 从一轮对话中提取新的事实并保存，同主题的事实替换旧记忆，超出上限时淘汰最不重要的旧记忆
+func (s *Store) Extract(userID, characterID, messageID int64, userContent, reply string) error {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	existing, err := chatRepo.GetMemories(userID, characterID)
+	if err != nil {
+		return err
+	}
+
+	known := make([]string, 0, len(existing))
+	for _, memory := range existing {
+		if memory.FactKey != "" {
+			known = append(known, fmt.Sprintf("[%s] %s", memory.FactKey, memory.Content))
+		} else {
+			known = append(known, memory.Content)
+		}
+	}
+
+	provider, err := s.svcCtx.LLM.Get(s.svcCtx.Config.Memory.Provider)
+	if err != nil {
+		return err
+	}
+	result, err := prompt.Generate(s.ctx, provider.ChatModel, prompt.BuildMemoryExtractMessages(known, userContent, reply))
+	if err != nil {
+		return err
+	}
+	extracted, err := prompt.ParseExtractedMemories(result.Content)
+	if err != nil {
+		s.Infof("No memory extracted - MessageId: %d, Reason: %v", messageID, err)
+		return nil
+	}
+
+	created, replaced := mergeMemories(existing, extracted, userID, characterID, messageID)
+	for _, memory := range replaced {
+		if err := chatRepo.ReplaceMemory(memory); err != nil {
+			return err
+		}
+	}
+	if err := chatRepo.CreateMemories(created); err != nil {
+		return err
+	}
+
+	return s.evict(userID, characterID)
+}
+
+// mergeMemories 合并新提取的事实：与已有记忆主题相同的替换旧记忆，内容重复的跳过，其余新建。
+// 同一批中主题重复时以最后一条为准
+func mergeMemories(existing []model.UserCharacterMemory, extracted []prompt.ExtractedMemory, userID, characterID, messageID int64) (created, replaced []*model.UserCharacterMemory) {
+	seen := make(map[string]bool, len(existing))
+	byKey := make(map[string]*model.UserCharacterMemory, len(existing))
+	for i := range existing {
+		seen[normalizeMemory(existing[i].Content)] = true
+		if key := normalizeMemory(existing[i].FactKey); key != "" {
+			byKey[key] = &existing[i]
+		}
+	}
+
+	for _, item := range extracted {
+		content := truncateRunes(strings.TrimSpace(item.Content), maxMemoryLength)
+		if content == "" {
+			continue
+		}
+		factKey := truncateRunes(strings.TrimSpace(item.Key), maxFactKeyLength)
+		key := normalizeMemory(factKey)
+		if key != "" && byKey[key] != nil {
+			memory := byKey[key]
+			if normalizeMemory(memory.Content) == normalizeMemory(content) {
+				continue
+			}
+			memory.Content = content
+			memory.Importance = clampImportance(item.Importance)
+			memory.SourceMessageID = &messageID
+			// 已有记忆只替换一次，同一批的后续同主题事实直接覆盖该条
+			if memory.ID > 0 && !slices.Contains(replaced, memory) {
+				replaced = append(replaced, memory)
+			}
+			seen[normalizeMemory(content)] = true
+			continue
+		}
+		if seen[normalizeMemory(content)] {
+			continue
+		}
+		seen[normalizeMemory(content)] = true
+		memory := &model.UserCharacterMemory{
+			UserID:          userID,
+			CharacterID:     characterID,
+			FactKey:         factKey,
+			Content:         content,
+			Importance:      clampImportance(item.Importance),
+			SourceMessageID: &messageID,
+			Status:          common.Normal,
+		}
+		if key != "" {
+			byKey[key] = memory
+		}
+		created = append(created, memory)
+	}
+	return created, replaced
+}
+
+// Retrieve 返回与当前问题最相关的若干条记忆
+func (s *Store) Retrieve(userID, characterID int64, query string, limit int) ([]string, error) {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	memories, err := chatRepo.GetMemories(userID, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := rankMemories(memories, query, limit)
+	facts := make([]string, 0, len(ranked))
+	for _, memory := range ranked {
+		facts = append(facts, memory.Content)
+	}
+	return facts, nil
+}
+
+// evict 记忆数超过上限时，软删除重要程度最低且最久未更新的记忆
+func (s *Store) evict(userID, characterID int64) error {
+	maxMemories := s.svcCtx.Config.Memory.MaxMemories
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	memories, err := chatRepo.GetMemories(userID, characterID)
+	if err != nil || len(memories) <= maxMemories {
+		return err
+	}
+
+	// GetMemories 已按重要程度和更新时间倒序，末尾即淘汰对象
+	for _, memory := range memories[maxMemories:] {
+		if err := chatRepo.DeleteMemory(memory.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rankMemories 按与问题的字词重合度和重要程度打分，取前 limit 条
+func rankMemories(memories []model.UserCharacterMemory, query string, limit int) []model.UserCharacterMemory {
+	queryGrams := bigrams(query)
+	type scored struct {
+		memory model.UserCharacterMemory
+		score  float64
+	}
+	candidates := make([]scored, 0, len(memories))
+	for i, memory := range memories {
+		overlap := 0
+		for gram := range bigrams(memory.Content) {
+			if queryGrams[gram] {
+				overlap++
+			}
+		}
+		// 输入已按更新时间倒序，越新的记忆加分越多
+		recency := 1 - float64(i)/float64(len(memories)+1)
+		candidates = append(candidates, scored{
+			memory: memory,
+			score:  float64(overlap)*2 + float64(memory.Importance) + recency,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	result := make([]model.UserCharacterMemory, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, candidate.memory)
+	}
+	return result
+}
+
+// bigrams 提取相邻字符二元组，中文无需分词即可比较相似度
+func bigrams(text string) map[string]bool {
+	runes := make([]rune, 0, len(text))
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	grams := make(map[string]bool, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = true
+	}
+	return grams
+}
+
+func normalizeMemory(content string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(content, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}), ""))
+}
+
+func clampImportance(importance int32) int32 {
+	if importance < 1 {
+		return 1
+	}
+	if importance > 5 {
+		return 5
+	}
+	return importance
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"testing"
+
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/model"
+)
+
+func TestMergeMemoriesReplacesSameKey(t *testing.T) {
+	existing := []model.UserCharacterMemory{
+		{ID: 1, FactKey: "居住地", Content: "用户住在北京", Importance: 3},
+		{ID: 2, FactKey: "姓名", Content: "用户叫小李", Importance: 5},
+		{ID: 3, Content: "用户喜欢猫", Importance: 2},
+	}
+	extracted := []prompt.ExtractedMemory{
+		{Key: "居住地", Content: "用户搬到了上海", Importance: 4},
+		{Key: "姓名", Content: "用户叫小李。", Importance: 5}, // 内容未变
+		{Key: "爱好", Content: "用户喜欢猫"},                 // 与无主题的旧记忆重复
+		{Key: "专业", Content: "用户学物理", Importance: 3},
+		{Key: "专业", Content: "用户转到了数学专业", Importance: 4}, // 同一批以最后一条为准
+	}
+
+	created, replaced := mergeMemories(existing, extracted, 1, 2, 100)
+
+	if len(replaced) != 1 || replaced[0].ID != 1 || replaced[0].Content != "用户搬到了上海" ||
+		replaced[0].Importance != 4 || *replaced[0].SourceMessageID != 100 {
+		t.Fatalf("replaced = %+v", replaced)
+	}
+	if len(created) != 1 || created[0].FactKey != "专业" || created[0].Content != "用户转到了数学专业" {
+		t.Fatalf("created = %+v", created)
+	}
+	if created[0].UserID != 1 || created[0].CharacterID != 2 {
+		t.Errorf("created owner = %d/%d", created[0].UserID, created[0].CharacterID)
+	}
+}
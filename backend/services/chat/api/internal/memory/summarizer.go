@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	summaryLockKey = "chat:summary:lock:%d"
+	summaryLockTTL = 2 * time.Minute
+)
+
+// unlockScript 锁的值仍是自己的令牌时才删除，摘要超过锁的有效期后不会误删其他实例的锁
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Summarizer 把超出最近窗口的旧消息压缩为滚动摘要
+type Summarizer struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSummarizer(ctx context.Context, svcCtx *svc.ServiceContext) *Summarizer {
+	return &Summarizer{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// MaybeSummarize 待摘要的消息累计达到阈值时，把它们合并进已有摘要
+func (s *Summarizer) MaybeSummarize(conversationID int64) error {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	existing, err := chatRepo.GetConversationSummary(conversationID)
+	if err != nil {
+		return err
+	}
+
+	var coveredID int64
+	if existing != nil {
+		coveredID = existing.CoveredMessageID
+	}
+	pending, err := s.pendingMessages(conversationID, coveredID)
+	if err != nil {
+		return err
+	}
+	if len(pending) < s.svcCtx.Config.Summary.Interval {
+		return nil
+	}
+
+	_, err = s.summarize(conversationID, existing, pending)
+	return err
+}
+
+// Regenerate 丢弃已有摘要，从第一条消息开始重新生成
+func (s *Summarizer) Regenerate(conversationID int64) (*model.ConversationSummary, error) {
+	pending, err := s.pendingMessages(conversationID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, fmt.Errorf("对话消息较少，暂不需要摘要")
+	}
+	return s.summarize(conversationID, nil, pending)
+}
+
+// Invalidate 在 parentID 之下切换分支时，若其后的消息已被摘要覆盖，旧摘要描述的就不再是当前路径，直接丢弃等待重新生成
+// parentID 为 0 表示切换的是对话的第一条消息
+func (s *Summarizer) Invalidate(conversationID int64, parentID int64) error {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	existing, err := chatRepo.GetConversationSummary(conversationID)
+	if err != nil || existing == nil || existing.CoveredMessageID <= parentID {
+		return err
+	}
+
+	s.Infof("Summary invalidated - ConversationId: %d, ParentId: %d", conversationID, parentID)
+	return chatRepo.DeleteConversationSummary(conversationID)
+}
+
+// pendingMessages 返回已摘要部分之后、最近 KeepRecent 条之前的消息
+func (s *Summarizer) pendingMessages(conversationID int64, coveredID int64) ([]model.Message, error) {
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	messages, err := chatRepo.GetMessagesAfter(conversationID, coveredID)
+	if err != nil {
+		return nil, err
+	}
+
+	keepRecent := s.svcCtx.Config.Summary.KeepRecent
+	if len(messages) <= keepRecent {
+		return nil, nil
+	}
+	return messages[:len(messages)-keepRecent], nil
+}
+
+func (s *Summarizer) summarize(conversationID int64, existing *model.ConversationSummary, pending []model.Message) (*model.ConversationSummary, error) {
+	// 同一对话同时只允许一个摘要任务，避免多实例重复调用模型
+	lockKey := fmt.Sprintf(summaryLockKey, conversationID)
+	token := uuid.NewString()
+	locked, err := s.svcCtx.Redis.SetNX(s.ctx, lockKey, token, summaryLockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("对话 %d 的摘要正在生成中", conversationID)
+	}
+	defer func() {
+		if err := unlockScript.Run(context.Background(), s.svcCtx.Redis, []string{lockKey}, token).Err(); err != nil {
+			s.Errorf("Release summary lock failed - ConversationId: %d, Error: %v", conversationID, err)
+		}
+	}()
+
+	provider, err := s.svcCtx.LLM.Get(s.svcCtx.Config.Summary.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous string
+	var messageCount int32
+	if existing != nil {
+		previous = existing.Summary
+		messageCount = existing.MessageCount
+	}
+
+	s.Infof("Summarizing conversation %d - Pending: %d, Provider: %s", conversationID, len(pending), provider.Name)
+	result, err := prompt.Generate(s.ctx, provider.ChatModel, prompt.BuildSummaryMessages(previous, pending))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.ConversationSummary{
+		ConversationID:   conversationID,
+		Summary:          strings.TrimSpace(result.Content),
+		CoveredMessageID: pending[len(pending)-1].ID,
+		MessageCount:     messageCount + int32(len(pending)),
+		UpdatedAt:        time.Now(),
+	}
+	chatRepo := repo.NewChatServiceRepo(s.ctx, s.svcCtx)
+	if err := chatRepo.SaveConversationSummary(summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/breaker"
+)
+
+// ErrNoProvider 所有提供方都处于熔断状态
+var ErrNoProvider = errors.New("所有模型提供方暂时不可用")
+
+// Attempt 一次提供方调用的结果，记录在消息元数据中
+type Attempt struct {
+	Provider  string `json:"provider"`
+	Error     string `json:"error,omitempty"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+}
+
+// Stream 已收到首个分片的流式输出
+type Stream struct {
+	Provider *Provider
+	first    *schema.Message
+	firstErr error
+	started  bool
+	reader   *schema.StreamReader[*schema.Message]
+	cancel   context.CancelFunc
+}
+
+// Recv 先返回等待时收到的首个分片，再继续读取
+func (s *Stream) Recv() (*schema.Message, error) {
+	if !s.started {
+		s.started = true
+		return s.first, s.firstErr
+	}
+	return s.reader.Recv()
+}
+
+func (s *Stream) Close() {
+	s.reader.Close()
+	s.cancel()
+}
+
+// StreamWithFailover 按顺序调用提供方，直到收到首个分片（内容或工具调用）；
+// 连接失败、首个分片出错或超过 firstTokenTimeout 时切换到下一个，熔断中的提供方直接跳过。
+// 收到首个分片后不再切换，避免客户端收到两个提供方拼接的回复
+func StreamWithFailover(ctx context.Context, providers []*Provider, messages []*schema.Message,
+	tools []*schema.ToolInfo, firstTokenTimeout time.Duration) (*Stream, []Attempt, error) {
+	attempts := make([]Attempt, 0, len(providers))
+	lastErr := ErrNoProvider
+	for _, provider := range providers {
+		startedAt := time.Now()
+		promise, err := provider.Breaker.Allow()
+		if err != nil {
+			attempts = append(attempts, Attempt{Provider: provider.Name, Error: "熔断中"})
+			continue
+		}
+
+		stream, err := openStream(ctx, provider, messages, tools, firstTokenTimeout)
+		elapsed := time.Since(startedAt).Milliseconds()
+		if err == nil {
+			promise.Accept()
+			attempts = append(attempts, Attempt{Provider: provider.Name, ElapsedMs: elapsed})
+			// 流刚打开时用户已停止或整体超时，由这里关闭，调用方按停止处理时不会泄漏连接
+			if ctx.Err() != nil {
+				stream.Close()
+				return nil, attempts, ctx.Err()
+			}
+			return stream, attempts, nil
+		}
+		// 用户停止或整体超时不算提供方的失败
+		if ctx.Err() != nil {
+			return nil, attempts, ctx.Err()
+		}
+		promise.Reject(err.Error())
+		attempts = append(attempts, Attempt{Provider: provider.Name, Error: err.Error(), ElapsedMs: elapsed})
+		lastErr = fmt.Errorf("%s: %w", provider.Name, err)
+	}
+	return nil, attempts, lastErr
+}
+
+// openStream 调用一个提供方并等待首个分片
+func openStream(ctx context.Context, provider *Provider, messages []*schema.Message,
+	tools []*schema.ToolInfo, firstTokenTimeout time.Duration) (*Stream, error) {
+	chatModel := provider.ChatModel
+	if len(tools) > 0 {
+		var err error
+		if chatModel, err = chatModel.WithTools(tools); err != nil {
+			return nil, err
+		}
+	}
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	reader, err := chatModel.Stream(attemptCtx, messages)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	type result struct {
+		msg *schema.Message
+		err error
+	}
+	firstCh := make(chan result, 1)
+	go func() {
+		msg, err := reader.Recv()
+		firstCh <- result{msg: msg, err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if firstTokenTimeout > 0 {
+		timer := time.NewTimer(firstTokenTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case first := <-firstCh:
+		if first.err != nil && first.err != io.EOF {
+			reader.Close()
+			cancel()
+			return nil, first.err
+		}
+		return &Stream{
+			Provider: provider,
+			first:    first.msg,
+			firstErr: first.err,
+			reader:   reader,
+			cancel:   cancel,
+		}, nil
+	case <-timeout:
+		err = fmt.Errorf("首个分片超时（%s）", firstTokenTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	// 取消后等待读取返回再关闭，避免与进行中的 Recv 并发
+	cancel()
+	go func() {
+		<-firstCh
+		reader.Close()
+	}()
+	return nil, err
+}
+
+func newBreaker(name string) breaker.Breaker {
+	return breaker.NewBreaker(breaker.WithName("llm:" + name))
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/config"
+
+	einoModel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/zeromicro/go-zero/core/breaker"
+)
+
+func newTestProvider(name string, conf config.MockConfig) *Provider {
+	return &Provider{
+		Name:      name,
+		ChatModel: NewMockChatModel(name, conf),
+		Breaker:   newBreaker(name),
+	}
+}
+
+func readStream(t *testing.T, stream *Stream) string {
+	t.Helper()
+	defer stream.Close()
+	var sb strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return sb.String()
+		}
+		sb.WriteString(chunk.Content)
+	}
+}
+
+func TestStreamWithFailover(t *testing.T) {
+	broken := newTestProvider("broken", config.MockConfig{Rules: []config.MockRule{{Keyword: "你好", Error: "connection refused"}}})
+	slow := newTestProvider("slow", config.MockConfig{DefaultReply: "太慢了", Latency: time.Second})
+	backup := newTestProvider("backup", config.MockConfig{DefaultReply: "备用回复"})
+	messages := []*schema.Message{schema.UserMessage("你好")}
+
+	stream, attempts, err := StreamWithFailover(context.Background(), []*Provider{broken, slow, backup}, messages, nil, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("StreamWithFailover failed: %v", err)
+	}
+	if stream.Provider != backup {
+		t.Errorf("expected backup provider, got %s", stream.Provider.Name)
+	}
+	if len(attempts) != 3 || attempts[2].Error != "" {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+	if !strings.Contains(attempts[1].Error, "超时") {
+		t.Errorf("expected first token timeout, got %q", attempts[2].Error)
+	}
+	if got := readStream(t, stream); got != "备用回复" {
+		t.Errorf("reply = %q", got)
+	}
+
+	_, attempts, err = StreamWithFailover(context.Background(), []*Provider{broken}, messages, nil, time.Second)
+	if err == nil || len(attempts) != 1 {
+		t.Errorf("expected failure after all providers, got %v %+v", err, attempts)
+	}
+}
+
+// pipeChatModel 返回测试持有写端的流，用于观察读端是否被关闭
+type pipeChatModel struct {
+	reader *schema.StreamReader[*schema.Message]
+}
+
+func (m *pipeChatModel) Generate(context.Context, []*schema.Message, ...einoModel.Option) (*schema.Message, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m *pipeChatModel) Stream(context.Context, []*schema.Message, ...einoModel.Option) (*schema.StreamReader[*schema.Message], error) {
+	return m.reader, nil
+}
+
+func (m *pipeChatModel) WithTools([]*schema.ToolInfo) (einoModel.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// stopOnAccept 提供方收到首个分片、流已打开时模拟用户停止生成
+type stopOnAccept struct {
+	breaker.Breaker
+	stop func()
+}
+
+func (b stopOnAccept) Allow() (breaker.Promise, error) {
+	return b, nil
+}
+
+func (b stopOnAccept) Accept() {
+	b.stop()
+}
+
+func (b stopOnAccept) Reject(string) {}
+
+func TestStreamWithFailoverClosesStreamWhenStopped(t *testing.T) {
+	reader, writer := schema.Pipe[*schema.Message](1)
+	writer.Send(schema.AssistantMessage("你好", nil), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	provider := &Provider{
+		Name:      "pipe",
+		ChatModel: &pipeChatModel{reader: reader},
+		Breaker:   stopOnAccept{Breaker: newBreaker("pipe"), stop: cancel},
+	}
+
+	stream, attempts, err := StreamWithFailover(ctx, []*Provider{provider}, []*schema.Message{schema.UserMessage("你好")}, nil, time.Second)
+	if stream != nil || !errors.Is(err, context.Canceled) || len(attempts) != 1 {
+		t.Fatalf("StreamWithFailover = %v, %+v, %v", stream, attempts, err)
+	}
+	if closed := writer.Send(schema.AssistantMessage("继续", nil), nil); !closed {
+		t.Fatal("stream reader not closed after stop")
+	}
+}
+
+func TestRegistryChain(t *testing.T) {
+	a, b, c := newTestProvider("a", config.MockConfig{}), newTestProvider("b", config.MockConfig{}), newTestProvider("c", config.MockConfig{})
+	registry := &Registry{
+		providers:   map[string]*Provider{"a": a, "b": b, "c": c},
+		defaultName: "a",
+		fallbacks:   []string{"b", "c"},
+	}
+
+	names := func(chain []*Provider) string {
+		var parts []string
+		for _, p := range chain {
+			parts = append(parts, p.Name)
+		}
+		return strings.Join(parts, ",")
+	}
+	if got := names(registry.Chain(b, nil)); got != "b,c" {
+		t.Errorf("global chain = %s", got)
+	}
+	if got := names(registry.Chain(a, []string{"c", "unknown", "a"})); got != "a,c" {
+		t.Errorf("character chain = %s", got)
+	}
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ai-roleplay/services/chat/api/internal/config"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// MockChatModel 确定性模拟模型，回复内容只取决于输入和规则，不访问网络
+//
+// 用户消息包含规则关键字时按规则回复、返回错误或发起工具调用；
+// 上一条消息是工具结果时，直接把工具结果作为回复。
+type MockChatModel struct {
+	name  string
+	conf  config.MockConfig
+	tools []*schema.ToolInfo
+}
+
+func NewMockChatModel(name string, conf config.MockConfig) *MockChatModel {
+	if name == "" {
+		name = "mock"
+	}
+	if conf.ChunkSize <= 0 {
+		conf.ChunkSize = 4
+	}
+	return &MockChatModel{name: name, conf: conf}
+}
+
+func (m *MockChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	resp, rule := m.respond(input)
+	if rule != nil && rule.Error != "" {
+		return nil, errors.New(rule.Error)
+	}
+	if err := m.sleep(ctx); err != nil {
+		return nil, err
+	}
+	resp.ResponseMeta = m.responseMeta(input, resp)
+	return resp, nil
+}
+
+func (m *MockChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	resp, rule := m.respond(input)
+	if rule != nil && rule.Error != "" && rule.ErrorAfter <= 0 {
+		return nil, errors.New(rule.Error)
+	}
+
+	chunks := m.split(resp)
+	meta := m.responseMeta(input, resp)
+	sr, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		for i, chunk := range chunks {
+			if rule != nil && rule.Error != "" && i == rule.ErrorAfter {
+				sw.Send(nil, errors.New(rule.Error))
+				return
+			}
+			if err := m.sleep(ctx); err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			if i == len(chunks)-1 {
+				chunk.ResponseMeta = meta
+			}
+			if closed := sw.Send(chunk, nil); closed {
+				return
+			}
+		}
+		// ErrorAfter 不小于分片数时，在全部分片之后返回错误
+		if rule != nil && rule.Error != "" {
+			sw.Send(nil, errors.New(rule.Error))
+		}
+	}()
+	return sr, nil
+}
+
+func (m *MockChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return &MockChatModel{name: m.name, conf: m.conf, tools: tools}, nil
+}
+
+// respond 生成完整回复，同时返回命中的规则
+func (m *MockChatModel) respond(input []*schema.Message) (*schema.Message, *config.MockRule) {
+	if len(input) > 0 && input[len(input)-1].Role == schema.Tool {
+		return schema.AssistantMessage(fmt.Sprintf("[%s] 工具结果：%s", m.name, input[len(input)-1].Content), nil), nil
+	}
+
+	question := lastUserContent(input)
+	for i := range m.conf.Rules {
+		rule := &m.conf.Rules[i]
+		if rule.Keyword == "" || !strings.Contains(question, rule.Keyword) {
+			continue
+		}
+		if rule.ToolName != "" && m.hasTool(rule.ToolName) {
+			index := 0
+			return schema.AssistantMessage("", []schema.ToolCall{{
+				Index: &index,
+				ID:    fmt.Sprintf("call_%s_%d", rule.ToolName, len(input)),
+				Type:  "function",
+				Function: schema.FunctionCall{
+					Name:      rule.ToolName,
+					Arguments: rule.ToolArgs,
+				},
+			}}), rule
+		}
+		if rule.Reply != "" {
+			return schema.AssistantMessage(rule.Reply, nil), rule
+		}
+		return schema.AssistantMessage(m.defaultReply(question), nil), rule
+	}
+	return schema.AssistantMessage(m.defaultReply(question), nil), nil
+}
+
+func (m *MockChatModel) defaultReply(question string) string {
+	if m.conf.DefaultReply != "" {
+		return m.conf.DefaultReply
+	}
+	if question == "" {
+		return fmt.Sprintf("[%s] 你好！", m.name)
+	}
+	return fmt.Sprintf("[%s] 收到：%s", m.name, question)
+}
+
+// split 按 ChunkSize 个字符切分回复，工具调用作为单独一个分片
+func (m *MockChatModel) split(resp *schema.Message) []*schema.Message {
+	if len(resp.ToolCalls) > 0 {
+		return []*schema.Message{resp}
+	}
+
+	runes := []rune(resp.Content)
+	chunks := make([]*schema.Message, 0, len(runes)/m.conf.ChunkSize+1)
+	for start := 0; start < len(runes); start += m.conf.ChunkSize {
+		end := start + m.conf.ChunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, schema.AssistantMessage(string(runes[start:end]), nil))
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, schema.AssistantMessage("", nil))
+	}
+	return chunks
+}
+
+// responseMeta 以字符数作为 token 数，保证用量统计可预测
+func (m *MockChatModel) responseMeta(input []*schema.Message, resp *schema.Message) *schema.ResponseMeta {
+	promptTokens := 0
+	for _, msg := range input {
+		promptTokens += utf8.RuneCountInString(msg.Content)
+	}
+	completionTokens := utf8.RuneCountInString(resp.Content)
+	finishReason := "stop"
+	if len(resp.ToolCalls) > 0 {
+		finishReason = "tool_calls"
+		completionTokens += len(resp.ToolCalls[0].Function.Arguments)
+	}
+	return &schema.ResponseMeta{
+		FinishReason: finishReason,
+		Usage: &schema.TokenUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}
+}
+
+func (m *MockChatModel) hasTool(name string) bool {
+	for _, tool := range m.tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockChatModel) sleep(ctx context.Context) error {
+	if m.conf.Latency <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(m.conf.Latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func lastUserContent(input []*schema.Message) string {
+	for i := len(input) - 1; i >= 0; i-- {
+		if input[i].Role == schema.User {
+			return input[i].Content
+		}
+	}
+	return ""
+}
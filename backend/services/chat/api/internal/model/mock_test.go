@@ -0,0 +1,161 @@
+package model
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-roleplay/services/chat/api/internal/config"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func newTestMock() *MockChatModel {
+	return NewMockChatModel("mock", config.MockConfig{
+		ChunkSize: 2,
+		Rules: []config.MockRule{
+			{Keyword: "#fail", Error: "mock failure"},
+			{Keyword: "#broken", Reply: "半途而废的回复", Error: "stream broken", ErrorAfter: 2},
+			{Keyword: "#late", Reply: "短回复", Error: "late failure", ErrorAfter: 10},
+			{Keyword: "#dice", ToolName: "roll_dice", ToolArgs: `{"sides":6}`},
+			{Keyword: "你好", Reply: "你好呀"},
+		},
+	})
+}
+
+func readAll(t *testing.T, sr *schema.StreamReader[*schema.Message]) ([]*schema.Message, error) {
+	t.Helper()
+	defer sr.Close()
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+func TestMockChatModelStream(t *testing.T) {
+	m := newTestMock()
+	sr, err := m.Stream(context.Background(), []*schema.Message{schema.UserMessage("你好")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	chunks, err := readAll(t, sr)
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+
+	full, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		t.Fatalf("ConcatMessages failed: %v", err)
+	}
+	if full.Content != "你好呀" {
+		t.Fatalf("unexpected content: %q", full.Content)
+	}
+	if full.ResponseMeta == nil || full.ResponseMeta.Usage == nil || full.ResponseMeta.Usage.CompletionTokens != 3 {
+		t.Fatalf("unexpected usage: %+v", full.ResponseMeta)
+	}
+}
+
+func TestMockChatModelEcho(t *testing.T) {
+	m := newTestMock()
+	resp, err := m.Generate(context.Background(), []*schema.Message{schema.UserMessage("讲个故事")})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if resp.Content != "[mock] 收到：讲个故事" {
+		t.Fatalf("unexpected content: %q", resp.Content)
+	}
+}
+
+func TestMockChatModelErrors(t *testing.T) {
+	m := newTestMock()
+	if _, err := m.Stream(context.Background(), []*schema.Message{schema.UserMessage("#fail")}); err == nil {
+		t.Fatal("expected immediate error")
+	}
+
+	sr, err := m.Stream(context.Background(), []*schema.Message{schema.UserMessage("#broken")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	chunks, err := readAll(t, sr)
+	if err == nil || err.Error() != "stream broken" {
+		t.Fatalf("expected mid-stream error, got %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks before error, got %d", len(chunks))
+	}
+	// ErrorAfter 超过分片数时错误不能被丢弃
+	sr, err = m.Stream(context.Background(), []*schema.Message{schema.UserMessage("#late")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	chunks, err = readAll(t, sr)
+	if err == nil || err.Error() != "late failure" {
+		t.Fatalf("expected error after last chunk, got %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected all 2 chunks before error, got %d", len(chunks))
+	}
+}
+
+func TestMockChatModelToolCall(t *testing.T) {
+	m := newTestMock()
+	input := []*schema.Message{schema.UserMessage("#dice")}
+
+	// 未绑定工具时按普通回复处理
+	resp, err := m.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 0 {
+		t.Fatalf("unexpected tool call without bound tools")
+	}
+
+	withTools, err := m.WithTools([]*schema.ToolInfo{{Name: "roll_dice"}})
+	if err != nil {
+		t.Fatalf("WithTools failed: %v", err)
+	}
+	resp, err = withTools.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "roll_dice" {
+		t.Fatalf("expected roll_dice tool call, got %+v", resp.ToolCalls)
+	}
+
+	input = append(input, resp, schema.ToolMessage("4", resp.ToolCalls[0].ID))
+	resp, err = withTools.Generate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(resp.Content, "4") {
+		t.Fatalf("expected tool result in reply, got %q", resp.Content)
+	}
+}
+
+func TestMockChatModelCancel(t *testing.T) {
+	m := NewMockChatModel("mock", config.MockConfig{ChunkSize: 1, Latency: 50 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	sr, err := m.Stream(ctx, []*schema.Message{schema.UserMessage("一段比较长的消息")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if _, err := sr.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	cancel()
+	if _, err := readAll(t, sr); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+
+	"ai-roleplay/services/chat/api/internal/config"
+
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Ollama 提供 OpenAI 兼容接口，默认监听本机 11434 端口
+const defaultOllamaURL = "http://localhost:11434/v1"
+
+func newOpenAIChatModel(ctx context.Context, c config.ProviderConfig, apiKey string) (model.ToolCallingChatModel, error) {
+	modelConf := &openai.ChatModelConfig{
+		BaseURL: c.BaseURL,
+		Model:   c.Model,
+		APIKey:  apiKey,
+		Timeout: c.Timeout,
+	}
+	if c.Temperature > 0 {
+		temperature := c.Temperature
+		modelConf.Temperature = &temperature
+	}
+	if c.MaxTokens > 0 {
+		maxTokens := c.MaxTokens
+		modelConf.MaxTokens = &maxTokens
+	}
+	return openai.NewChatModel(ctx, modelConf)
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"ai-roleplay/services/chat/api/internal/config"
+	"ai-roleplay/services/chat/api/internal/history"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/zeromicro/go-zero/core/breaker"
+)
+
+// 提供方类型
+const (
+	ProviderTypeOpenAI = "openai" // OpenAI 兼容接口（DeepSeek、通义等）
+	ProviderTypeOllama = "ollama" // 本地 Ollama 服务
+	ProviderTypeMock   = "mock"   // 确定性模拟模型，用于离线开发和测试
+)
+
+// defaultReplyReserve 未配置 MaxTokens 时为回复预留的token数
+const defaultReplyReserve = 1024
+
+// Provider 已创建的模型提供方
+type Provider struct {
+	Name          string
+	Type          string
+	Model         string
+	ContextWindow int
+	MaxTokens     int
+	Tokenizer     history.Tokenizer
+	ChatModel     model.ToolCallingChatModel
+	Breaker       breaker.Breaker // 连续失败时熔断，熔断期间直接切换到备用提供方
+}
+
+// ReplyReserve 为模型回复预留的token数
+func (p *Provider) ReplyReserve() int {
+	if p.MaxTokens > 0 {
+		return p.MaxTokens
+	}
+	return defaultReplyReserve
+}
+
+// Registry 按名称管理所有模型提供方
+type Registry struct {
+	providers   map[string]*Provider
+	defaultName string
+	fallbacks   []string
+}
+
+// NewRegistry 根据配置创建所有模型提供方
+func NewRegistry(ctx context.Context, c config.LLMConfig) (*Registry, error) {
+	if len(c.Providers) == 0 {
+		return nil, fmt.Errorf("no llm provider configured")
+	}
+
+	registry := &Registry{
+		providers:   make(map[string]*Provider, len(c.Providers)),
+		defaultName: c.Default,
+		fallbacks:   c.Fallbacks,
+	}
+	for _, providerConf := range c.Providers {
+		if _, ok := registry.providers[providerConf.Name]; ok {
+			return nil, fmt.Errorf("duplicate llm provider %q", providerConf.Name)
+		}
+		chatModel, err := newChatModel(ctx, providerConf)
+		if err != nil {
+			return nil, fmt.Errorf("create llm provider %q failed: %w", providerConf.Name, err)
+		}
+		registry.providers[providerConf.Name] = &Provider{
+			Name:          providerConf.Name,
+			Type:          providerConf.Type,
+			Model:         providerConf.Model,
+			ContextWindow: providerConf.ContextWindow,
+			MaxTokens:     providerConf.MaxTokens,
+			Tokenizer:     history.NewTokenizer(providerConf.Tokenizer),
+			ChatModel:     chatModel,
+			Breaker:       newBreaker(providerConf.Name),
+		}
+	}
+
+	if registry.defaultName == "" {
+		registry.defaultName = c.Providers[0].Name
+	}
+	if _, ok := registry.providers[registry.defaultName]; !ok {
+		return nil, fmt.Errorf("default llm provider %q not found", registry.defaultName)
+	}
+	for _, name := range registry.fallbacks {
+		if _, ok := registry.providers[name]; !ok {
+			return nil, fmt.Errorf("fallback llm provider %q not found", name)
+		}
+	}
+	return registry, nil
+}
+
+// Get 按名称获取提供方，名称为空时返回默认提供方
+func (r *Registry) Get(name string) (*Provider, error) {
+	if name == "" {
+		name = r.defaultName
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("llm provider %q not found", name)
+	}
+	return provider, nil
+}
+
+// Chain 返回按顺序尝试的提供方：先是选中的提供方，再是备用提供方；
+// fallbacks 为角色配置的备用提供方，为空时使用全局配置，未知和重复的名称被忽略
+func (r *Registry) Chain(primary *Provider, fallbacks []string) []*Provider {
+	if len(fallbacks) == 0 {
+		fallbacks = r.fallbacks
+	}
+	chain := []*Provider{primary}
+	for _, name := range fallbacks {
+		provider, ok := r.providers[name]
+		if !ok || slices.Contains(chain, provider) {
+			continue
+		}
+		chain = append(chain, provider)
+	}
+	return chain
+}
+
+// Default 返回默认提供方名称
+func (r *Registry) Default() string {
+	return r.defaultName
+}
+
+// Names 返回所有提供方名称
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newChatModel(ctx context.Context, c config.ProviderConfig) (model.ToolCallingChatModel, error) {
+	switch c.Type {
+	case ProviderTypeOpenAI:
+		return newOpenAIChatModel(ctx, c, resolveAPIKey(c))
+	case ProviderTypeOllama:
+		if c.BaseURL == "" {
+			c.BaseURL = defaultOllamaURL
+		}
+		return newOpenAIChatModel(ctx, c, resolveAPIKey(c))
+	case ProviderTypeMock:
+		return NewMockChatModel(c.Model, c.Mock), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider type %q", c.Type)
+	}
+}
+
+// resolveAPIKey 环境变量中的密钥优先，避免把密钥写进配置文件
+func resolveAPIKey(c config.ProviderConfig) string {
+	if c.APIKeyEnv != "" {
+		if key := os.Getenv(c.APIKeyEnv); key != "" {
+			return key
+		}
+	}
+	return c.APIKey
+}
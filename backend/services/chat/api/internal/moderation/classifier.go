@@ -0,0 +1,40 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+
+	"ai-roleplay/services/chat/api/internal/prompt"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Classifier 用大模型判断内容是否违规
+type Classifier struct {
+	ClassifierConfig
+	chatModel model.ToolCallingChatModel
+}
+
+func NewClassifier(c ClassifierConfig, chatModel model.ToolCallingChatModel) *Classifier {
+	return &Classifier{ClassifierConfig: c, chatModel: chatModel}
+}
+
+func (c *Classifier) Check(ctx context.Context, text string) ([]Finding, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	result, err := prompt.Generate(ctx, c.chatModel, prompt.BuildModerationMessages(text))
+	if err != nil {
+		return nil, err
+	}
+	classification, err := prompt.ParseClassification(result.Content)
+	if err != nil || classification.Safe {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(classification.Category + " " + classification.Reason)
+	if reason == "" {
+		reason = "模型判定为不安全内容"
+	}
+	return []Finding{{Checker: "classifier", Action: c.Action, Reason: reason}}, nil
+}
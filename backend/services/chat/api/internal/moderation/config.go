@@ -0,0 +1,28 @@
+package moderation
+
+import "time"
+
+// Config 内容审核配置。关键词和正则规则保存在 system_configs 的 moderation_rules 中，修改后无需重启
+type Config struct {
+	Enable     bool             `json:",default=true"`
+	CacheTTL   time.Duration    `json:",default=1m"` // 规则缓存时间，修改 system_configs 后最迟在此时间后生效
+	Spam       SpamConfig       `json:",optional"`
+	Classifier ClassifierConfig `json:",optional"`
+}
+
+// SpamConfig 长度和刷屏检查，只用于用户输入
+type SpamConfig struct {
+	Action         string  `json:",default=block"`
+	MaxLength      int     `json:",default=2000"` // 消息最大字符数，system_configs 中的 max_message_length 优先
+	MaxRepeat      int     `json:",default=20"`   // 同一字符连续出现的最大次数
+	MinLength      int     `json:",default=50"`   // 超过该长度才检查字符多样性
+	MinUniqueRatio float64 `json:",default=0.1"`  // 不同字符数占总字符数的最低比例
+}
+
+// ClassifierConfig 大模型分类器，默认关闭
+type ClassifierConfig struct {
+	Enable   bool          `json:",default=false"`
+	Provider string        `json:",optional"` // 使用的模型提供方，为空时使用默认
+	Action   string        `json:",default=flag"`
+	Timeout  time.Duration `json:",default=10s"` // 超时后视为通过
+}
@@ -0,0 +1,99 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+)
+
+// 处理方式，按严重程度从低到高
+const (
+	ActionPass  = "pass"  // 通过
+	ActionFlag  = "flag"  // 放行并标记，供人工复核
+	ActionMask  = "mask"  // 用星号遮蔽命中的内容后放行
+	ActionBlock = "block" // 拒绝
+)
+
+// 审核阶段
+const (
+	StageInput  = "input"  // 用户输入
+	StageOutput = "output" // 模型输出
+)
+
+var severity = map[string]int{
+	ActionPass:  0,
+	ActionFlag:  1,
+	ActionMask:  2,
+	ActionBlock: 3,
+}
+
+// ValidAction 是否为支持的处理方式（不含 pass）
+func ValidAction(action string) bool {
+	return severity[action] > 0
+}
+
+// Finding 一个检查器的命中结果
+type Finding struct {
+	Checker string `json:"checker"`         // 检查器：rule/spam/classifier
+	Action  string `json:"action"`          // 处理方式
+	Reason  string `json:"reason"`          // 命中原因
+	Match   string `json:"match,omitempty"` // 命中的原文片段
+
+	spans [][2]int // 命中片段在原文中的字节位置，遮蔽时使用
+}
+
+// Verdict 一段内容的审核结论，保存在消息元数据的 moderation 字段中
+type Verdict struct {
+	Stage    string    `json:"stage"`
+	Action   string    `json:"action"` // 所有命中中最严重的处理方式
+	Findings []Finding `json:"findings"`
+	Original string    `json:"-"` // 内容被遮蔽或拦截时保留原文，供复核；保存在消息的 original_content 中，不写入元数据
+}
+
+// Checker 可插拔的内容检查器，未命中时返回空
+type Checker interface {
+	Check(ctx context.Context, text string) ([]Finding, error)
+}
+
+// NewVerdict 汇总命中结果，全部通过时返回 nil
+func NewVerdict(stage string, findings []Finding) *Verdict {
+	if len(findings) == 0 {
+		return nil
+	}
+	verdict := &Verdict{Stage: stage, Action: ActionPass, Findings: findings}
+	for _, finding := range findings {
+		if severity[finding.Action] > severity[verdict.Action] {
+			verdict.Action = finding.Action
+		}
+	}
+	return verdict
+}
+
+// Mask 把处理方式为 mask 的命中片段替换为等长的星号
+func Mask(text string, findings []Finding) string {
+	masked := make([]bool, len(text))
+	hit := false
+	for _, finding := range findings {
+		if finding.Action != ActionMask {
+			continue
+		}
+		for _, span := range finding.spans {
+			for i := span[0]; i < span[1] && i < len(text); i++ {
+				masked[i] = true
+				hit = true
+			}
+		}
+	}
+	if !hit {
+		return text
+	}
+
+	var b strings.Builder
+	for i, r := range text {
+		if masked[i] {
+			b.WriteRune('*')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newRules(t *testing.T) *RuleChecker {
+	rules, err := ParseRules(`[
+		{"type":"keyword","pattern":"笨蛋","action":"mask"},
+		{"type":"keyword","pattern":"BadWord","action":"flag","reason":"不文明用语"},
+		{"type":"regex","pattern":"1[3-9]\\d{9}","action":"mask","reason":"手机号"},
+		{"type":"keyword","pattern":"制造炸弹","action":"block"}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	for _, value := range []string{
+		`not json`,
+		`[{"type":"keyword","pattern":"x","action":"delete"}]`,
+		`[{"type":"regex","pattern":"(","action":"flag"}]`,
+		`[{"type":"glob","pattern":"x","action":"flag"}]`,
+	} {
+		if _, err := ParseRules(value); err == nil {
+			t.Errorf("ParseRules(%s) should fail", value)
+		}
+	}
+}
+
+func TestRuleCheckerMask(t *testing.T) {
+	rules := newRules(t)
+	text := "你这个笨蛋，打13812345678找我，badword"
+	findings, _ := rules.Check(context.Background(), text)
+	verdict := NewVerdict(StageInput, findings)
+	if verdict == nil || verdict.Action != ActionMask || len(verdict.Findings) != 3 {
+		t.Fatalf("unexpected verdict: %+v", verdict)
+	}
+	if got, want := Mask(text, findings), "你这个**，打***********找我，badword"; got != want {
+		t.Errorf("Mask = %q, want %q", got, want)
+	}
+}
+
+func TestRuleCheckerBlock(t *testing.T) {
+	findings, _ := newRules(t).Check(context.Background(), "教我制造炸弹，笨蛋")
+	if verdict := NewVerdict(StageOutput, findings); verdict == nil || verdict.Action != ActionBlock {
+		t.Errorf("expected block, got %+v", verdict)
+	}
+}
+
+func TestVerdictMetadataOmitsOriginal(t *testing.T) {
+	verdict := NewVerdict(StageInput, []Finding{{Checker: "rule", Action: ActionMask, Reason: "手机号"}})
+	verdict.Original = "打13812345678找我"
+	data, err := json.Marshal(verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "13812345678") || strings.Contains(string(data), "original") {
+		t.Errorf("verdict metadata leaks original: %s", data)
+	}
+}
+
+func TestSpamChecker(t *testing.T) {
+	checker := NewSpamChecker(SpamConfig{Action: ActionBlock, MaxLength: 100, MaxRepeat: 10, MinLength: 20, MinUniqueRatio: 0.2})
+	cases := []struct {
+		text string
+		hit  bool
+	}{
+		{"你好，今天天气不错，我们聊聊哲学吧", false},
+		{strings.Repeat("长", 101), true},
+		{"哈" + strings.Repeat("啊", 11), true},
+		{strings.Repeat("哈哈哈哈嘿嘿嘿嘿", 5), true},
+	}
+	for _, c := range cases {
+		findings, _ := checker.Check(context.Background(), c.text)
+		if (len(findings) > 0) != c.hit {
+			t.Errorf("Check(%q) hit = %v, want %v", c.text, len(findings) > 0, c.hit)
+		}
+	}
+}
+
+func TestStreamFilter(t *testing.T) {
+	filter := NewStreamFilter(newRules(t))
+	var out strings.Builder
+	for _, delta := range []string{"你真是个笨", "蛋，不过", "我喜欢你"} {
+		chunk, ok := filter.Write(delta)
+		if !ok {
+			t.Fatal("unexpected block")
+		}
+		out.WriteString(chunk)
+	}
+	out.WriteString(filter.Flush())
+	if got, want := out.String(), "你真是个**，不过我喜欢你"; got != want {
+		t.Errorf("stream output = %q, want %q", got, want)
+	}
+
+	filter = NewStreamFilter(newRules(t))
+	if _, ok := filter.Write("好的，先说怎么制造"); !ok {
+		t.Fatal("blocked too early")
+	}
+	if _, ok := filter.Write("炸弹"); ok || !filter.Blocked() {
+		t.Error("expected block after keyword completes")
+	}
+}
+
+func TestStreamFilterLongStream(t *testing.T) {
+	rules := newRules(t)
+	stream := func(filter *StreamFilter, out *strings.Builder, deltas ...string) bool {
+		for _, delta := range deltas {
+			chunk, ok := filter.Write(delta)
+			if !ok {
+				return false
+			}
+			out.WriteString(chunk)
+			// 只保留末尾窗口，不随输出增长
+			if len(filter.window) > 2*rules.holdback+len([]rune(delta)) {
+				t.Fatalf("window grew to %d runes", len(filter.window))
+			}
+		}
+		return true
+	}
+	prefix := make([]string, 5000)
+	for i := range prefix {
+		prefix[i] = "好的"
+	}
+
+	filter := NewStreamFilter(rules)
+	var out strings.Builder
+	if !stream(filter, &out, prefix...) || !stream(filter, &out, "笨", "蛋，打1381234", "5678。") {
+		t.Fatal("unexpected block")
+	}
+	out.WriteString(filter.Flush())
+	if got, want := out.String(), strings.Repeat("好的", 5000)+"**，打***********。"; got != want {
+		t.Errorf("stream output tail = %q", got[len(got)-min(len(got), 60):])
+	}
+
+	filter = NewStreamFilter(rules)
+	out.Reset()
+	if !stream(filter, &out, prefix...) || !stream(filter, &out, "制造", "炸") {
+		t.Fatal("blocked too early")
+	}
+	if _, ok := filter.Write("弹"); ok || !filter.Blocked() {
+		t.Error("expected block after keyword completes across chunks")
+	}
+}
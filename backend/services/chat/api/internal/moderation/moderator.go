@@ -0,0 +1,127 @@
+package moderation
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// system_configs 中的审核配置
+const (
+	rulesConfigKey     = "moderation_rules"   // JSON数组，见 Rule
+	maxLengthConfigKey = "max_message_length" // 用户消息最大字符数
+)
+
+// Moderator 组合关键词规则、刷屏检查和可选的大模型分类器，对用户输入和模型输出做审核
+type Moderator struct {
+	db         *gorm.DB
+	conf       Config
+	classifier *Classifier // 未开启时为 nil
+
+	mu       sync.RWMutex
+	rules    *RuleChecker
+	spam     *SpamChecker
+	loadedAt time.Time
+}
+
+func NewModerator(db *gorm.DB, c Config, classifier *Classifier) *Moderator {
+	return &Moderator{
+		db:         db,
+		conf:       c,
+		classifier: classifier,
+	}
+}
+
+// Check 审核一段内容，返回结论和处理后的内容，全部通过时结论为 nil；单个检查器出错时跳过，不影响对话
+func (m *Moderator) Check(ctx context.Context, stage, text string) (*Verdict, string) {
+	rules, spam := m.load(ctx)
+	checkers := []Checker{rules}
+	if stage == StageInput {
+		checkers = append(checkers, spam)
+	}
+	if m.classifier != nil {
+		checkers = append(checkers, m.classifier)
+	}
+
+	var findings []Finding
+	for _, checker := range checkers {
+		found, err := checker.Check(ctx, text)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("Moderation check failed - Stage: %s, Checker: %T, Error: %v", stage, checker, err)
+			continue
+		}
+		findings = append(findings, found...)
+	}
+
+	verdict := NewVerdict(stage, findings)
+	if verdict == nil {
+		return nil, text
+	}
+	result := Mask(text, findings)
+	if verdict.Action == ActionBlock || result != text {
+		verdict.Original = text
+	}
+	return verdict, result
+}
+
+// NewStreamFilter 创建流式输出过滤器，使用当前生效的关键词和正则规则
+func (m *Moderator) NewStreamFilter(ctx context.Context) *StreamFilter {
+	rules, _ := m.load(ctx)
+	return NewStreamFilter(rules)
+}
+
+// load 读取 system_configs 中的规则并缓存，读取失败时沿用上一次的规则
+func (m *Moderator) load(ctx context.Context) (*RuleChecker, *SpamChecker) {
+	m.mu.RLock()
+	rules, spam, loadedAt := m.rules, m.spam, m.loadedAt
+	m.mu.RUnlock()
+	if rules != nil && time.Since(loadedAt) < m.conf.CacheTTL {
+		return rules, spam
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rules != nil && time.Since(m.loadedAt) < m.conf.CacheTTL {
+		return m.rules, m.spam
+	}
+	if m.rules == nil {
+		m.rules, _ = NewRuleChecker(nil)
+		m.spam = NewSpamChecker(m.conf.Spam)
+	}
+	m.loadedAt = time.Now()
+
+	var configs []model.SystemConfig
+	if err := m.db.WithContext(ctx).Where("config_key IN ?", []string{rulesConfigKey, maxLengthConfigKey}).
+		Find(&configs).Error; err != nil {
+		logx.WithContext(ctx).Errorf("Load moderation configs failed: %v", err)
+		return m.rules, m.spam
+	}
+
+	spamConf := m.conf.Spam
+	for _, config := range configs {
+		if config.ConfigValue == nil {
+			continue
+		}
+		switch config.ConfigKey {
+		case rulesConfigKey:
+			parsed, err := ParseRules(*config.ConfigValue)
+			if err != nil {
+				logx.WithContext(ctx).Errorf("Parse moderation rules failed: %v", err)
+				continue
+			}
+			m.rules = parsed
+		case maxLengthConfigKey:
+			if maxLength, err := strconv.Atoi(*config.ConfigValue); err == nil && maxLength > 0 {
+				spamConf.MaxLength = maxLength
+			}
+		}
+	}
+	m.spam = NewSpamChecker(spamConf)
+	return m.rules, m.spam
+}
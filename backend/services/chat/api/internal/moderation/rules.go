@@ -0,0 +1,132 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 规则类型
+const (
+	RuleKeyword = "keyword" // 关键词，忽略大小写
+	RuleRegex   = "regex"   // 正则表达式
+)
+
+// regexHoldback 正则规则的匹配长度未知，流式输出时按该字符数保留末尾
+const regexHoldback = 16
+
+// Rule system_configs 中 moderation_rules 的一条规则
+type Rule struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+	keyword string
+	re      *regexp.Regexp
+}
+
+// RuleChecker 关键词和正则检查器
+type RuleChecker struct {
+	rules    []compiledRule
+	holdback int
+}
+
+// ParseRules 解析 moderation_rules 配置，格式错误的规则会返回错误
+func ParseRules(value string) (*RuleChecker, error) {
+	var rules []Rule
+	if strings.TrimSpace(value) != "" {
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return nil, fmt.Errorf("moderation_rules 不是有效的JSON: %w", err)
+		}
+	}
+	return NewRuleChecker(rules)
+}
+
+func NewRuleChecker(rules []Rule) (*RuleChecker, error) {
+	checker := &RuleChecker{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			continue
+		}
+		if !ValidAction(rule.Action) {
+			return nil, fmt.Errorf("第%d条规则的处理方式无效: %s", i+1, rule.Action)
+		}
+
+		compiled := compiledRule{Rule: rule}
+		switch rule.Type {
+		case RuleKeyword, "":
+			compiled.Type = RuleKeyword
+			compiled.keyword = strings.ToLower(rule.Pattern)
+			checker.holdback = max(checker.holdback, utf8.RuneCountInString(rule.Pattern)-1)
+		case RuleRegex:
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("第%d条规则的正则无效: %w", i+1, err)
+			}
+			compiled.re = re
+			checker.holdback = max(checker.holdback, regexHoldback)
+		default:
+			return nil, fmt.Errorf("第%d条规则的类型无效: %s", i+1, rule.Type)
+		}
+		checker.rules = append(checker.rules, compiled)
+	}
+	return checker, nil
+}
+
+// Check 返回每条命中规则的结果
+func (c *RuleChecker) Check(_ context.Context, text string) ([]Finding, error) {
+	var findings []Finding
+	lower := strings.ToLower(text)
+	for _, rule := range c.rules {
+		var spans [][2]int
+		if rule.re != nil {
+			for _, loc := range rule.re.FindAllStringIndex(text, -1) {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+			}
+		} else {
+			spans = keywordSpans(text, lower, rule)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		reason := rule.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("命中%s规则", rule.Type)
+		}
+		findings = append(findings, Finding{
+			Checker: "rule",
+			Action:  rule.Action,
+			Reason:  reason,
+			Match:   text[spans[0][0]:spans[0][1]],
+			spans:   spans,
+		})
+	}
+	return findings, nil
+}
+
+// keywordSpans 查找关键词的所有出现位置；大小写转换改变了字节长度时退回区分大小写的匹配
+func keywordSpans(text, lower string, rule compiledRule) [][2]int {
+	haystack, keyword := lower, rule.keyword
+	if len(lower) != len(text) {
+		haystack, keyword = text, rule.Pattern
+	}
+
+	var spans [][2]int
+	for start := 0; ; {
+		i := strings.Index(haystack[start:], keyword)
+		if i < 0 {
+			return spans
+		}
+		begin := start + i
+		spans = append(spans, [2]int{begin, begin + len(keyword)})
+		start = begin + len(keyword)
+	}
+}
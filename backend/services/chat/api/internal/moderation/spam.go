@@ -0,0 +1,51 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SpamChecker 检查超长消息和刷屏（大量重复字符）
+type SpamChecker struct {
+	SpamConfig
+}
+
+func NewSpamChecker(c SpamConfig) *SpamChecker {
+	return &SpamChecker{SpamConfig: c}
+}
+
+func (c *SpamChecker) Check(_ context.Context, text string) ([]Finding, error) {
+	length := utf8.RuneCountInString(text)
+	if c.MaxLength > 0 && length > c.MaxLength {
+		return c.finding(fmt.Sprintf("消息长度%d超过上限%d", length, c.MaxLength)), nil
+	}
+
+	run, longest := 0, 0
+	var prev rune
+	unique := make(map[rune]struct{})
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		unique[r] = struct{}{}
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		longest = max(longest, run)
+	}
+	if c.MaxRepeat > 0 && longest > c.MaxRepeat {
+		return c.finding(fmt.Sprintf("同一字符连续出现%d次", longest)), nil
+	}
+	if c.MinLength > 0 && length > c.MinLength && float64(len(unique)) < float64(length)*c.MinUniqueRatio {
+		return c.finding(fmt.Sprintf("%d个字符中只有%d种不同字符", length, len(unique))), nil
+	}
+	return nil, nil
+}
+
+func (c *SpamChecker) finding(reason string) []Finding {
+	return []Finding{{Checker: "spam", Action: c.Action, Reason: reason}}
+}
@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"context"
+)
+
+// StreamFilter 在流式输出时按规则即时审核：遮蔽命中的内容，命中拦截规则时停止输出。
+// 末尾可能与后续分片拼成敏感词的字符先保留，等后续内容到达或输出结束时再发送。
+// 每次只重新检查末尾窗口：最多 holdback 个已发送的字符作为上下文、保留未发送的字符和新分片，
+// 命中敏感词时其开头必然落在窗口内，检查耗时与已输出的总长度无关
+type StreamFilter struct {
+	rules   *RuleChecker
+	window  []rune // 末尾窗口的原文
+	sent    int    // 窗口中已发送的字符数
+	blocked bool
+}
+
+func NewStreamFilter(rules *RuleChecker) *StreamFilter {
+	return &StreamFilter{rules: rules}
+}
+
+// Write 追加模型输出的增量，返回可以发送给客户端的内容；命中拦截规则后返回 false，之后不再输出
+func (f *StreamFilter) Write(delta string) (string, bool) {
+	if f.blocked {
+		return "", false
+	}
+	f.window = append(f.window, []rune(delta)...)
+
+	masked, ok := f.check()
+	if !ok {
+		return "", false
+	}
+	out := f.take(masked, f.rules.holdback)
+	f.trim()
+	return out, true
+}
+
+// Flush 输出结束时返回保留的末尾内容
+func (f *StreamFilter) Flush() string {
+	if f.blocked {
+		return ""
+	}
+	masked, ok := f.check()
+	if !ok {
+		return ""
+	}
+	return f.take(masked, 0)
+}
+
+// Blocked 是否命中了拦截规则
+func (f *StreamFilter) Blocked() bool {
+	return f.blocked
+}
+
+// check 检查末尾窗口，返回遮蔽后的窗口内容；命中拦截规则时返回 false
+func (f *StreamFilter) check() ([]rune, bool) {
+	text := string(f.window)
+	findings, _ := f.rules.Check(context.Background(), text)
+	for _, finding := range findings {
+		if finding.Action == ActionBlock {
+			f.blocked = true
+			return nil, false
+		}
+	}
+	// 遮蔽按字符替换，字符数不变，可与原文窗口按位置对应
+	return []rune(Mask(text, findings)), true
+}
+
+// take 取出窗口中未发送且不在保留区的部分
+func (f *StreamFilter) take(masked []rune, holdback int) string {
+	end := len(masked) - holdback
+	if end <= f.sent {
+		return ""
+	}
+	out := string(masked[f.sent:end])
+	f.sent = end
+	return out
+}
+
+// trim 丢弃窗口开头多余的已发送字符，只保留 holdback 个作为下次检查的上下文
+func (f *StreamFilter) trim() {
+	drop := f.sent - f.rules.holdback
+	if drop <= 0 {
+		return
+	}
+	f.window = append(f.window[:0], f.window[drop:]...)
+	f.sent -= drop
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 
 	"github.com/cloudwego/eino/components/model"
@@ -12,80 +11,68 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
-func Generate(ctx context.Context, llm model.ToolCallingChatModel, in []*schema.Message) *schema.Message {
+func Generate(ctx context.Context, llm model.ToolCallingChatModel, in []*schema.Message) (*schema.Message, error) {
 	result, err := llm.Generate(ctx, in)
 	if err != nil {
-		log.Fatalf("llm generate failed: %v", err)
+		return nil, fmt.Errorf("llm generate failed: %w", err)
 	}
-	return result
+	return result, nil
 }
 
-func GenerateStream(ctx context.Context, llm model.ToolCallingChatModel, in []*schema.Message) *schema.StreamReader[*schema.Message] {
+func GenerateStream(ctx context.Context, llm model.ToolCallingChatModel, in []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
 	result, err := llm.Stream(ctx, in)
 	if err != nil {
-		log.Fatalf("llm generate failed: %v", err)
+		return nil, fmt.Errorf("llm stream failed: %w", err)
 	}
-	return result
+	return result, nil
 }
 
-func ReportStream(sr *schema.StreamReader[*schema.Message]) {
+// ReportStream 读取完整的流式输出并拼接为字符串
+func ReportStream(sr *schema.StreamReader[*schema.Message]) (string, error) {
 	defer sr.Close()
 	var contentBuffer strings.Builder
 	for {
 		recv, err := sr.Recv()
 		if err == io.EOF { // 流式输出结束
-			return
+			return contentBuffer.String(), nil
 		}
 		if err != nil {
-			log.Fatalf("recv failed : %v", err)
+			return contentBuffer.String(), fmt.Errorf("recv failed: %w", err)
 		}
 		if recv.Content != "" {
 			contentBuffer.WriteString(recv.Content)
 		}
 	}
-
 }
 
 func createTemplate() prompt.ChatTemplate {
 	// 创建模板，使用 FString 格式
 	return prompt.FromMessages(schema.FString,
-		// 系统消息模板
-		schema.SystemMessage("你是一个{role}。你需要用{style}的语气回答问题。你的目标是帮助程序员保持积极乐观的心态，提供技术建议的同时也要关注他们的心理健康。"),
+		// 平台策略
+		schema.SystemMessage("{policy}"),
+
+		// 角色设定
+		schema.SystemMessage("{persona}"),
 
 		// 插入需要的对话历史（新对话的话这里不填）
 		schema.MessagesPlaceholder("chat_history", true),
 
 		// 用户消息模板
-		schema.UserMessage("问题: {question}"),
+		schema.UserMessage("{question}"),
 	)
 }
 
-func CreateMessageFromTemplate(content string, chatHistory []*schema.Message) []*schema.Message {
+// CreateMessageFromTemplate 按 平台策略 -> 角色设定 -> 对话历史 -> 用户输入 的顺序组装消息
+func CreateMessageFromTemplate(characterPrompt *CharacterPrompt, content string, chatHistory []*schema.Message) ([]*schema.Message, error) {
 	template := createTemplate()
-	// 使用模板生成消息
-	fmt.Println("history", chatHistory)
-	// messages, err := template.Format(context.Background(), map[string]any{
-	// 	"role":     "程序员鼓励师",
-	// 	"style":    "积极、温暖且专业",
-	// 	"question": "我的代码一直报错，感觉好沮丧，该怎么办？",
-	// 	// "content":  content,
-	// 	// 对话历史（这个例子里模拟两轮对话历史）
-	// 	"chat_history": []*schema.Message{
-	// 		schema.UserMessage("你好"),
-	// 		schema.AssistantMessage("嘿！我是你的程序员鼓励师！记住，每个优秀的程序员都是从 Debug 中成长起来的。有什么我可以帮你的吗？", nil),
-	// 		schema.UserMessage("我觉得自己写的代码太烂了"),
-	// 		schema.AssistantMessage("每个程序员都经历过这个阶段！重要的是你在不断学习和进步。让我们一起看看代码，我相信通过重构和优化，它会变得更好。记住，Rome wasn't built in a day，代码质量是通过持续改进来提升的。", nil),
-	// 	},
-	// 	// "chat_history": chatHistory,
-	// })
 	messages, err := template.Format(context.Background(), map[string]any{
-		"role":         "程序员鼓励师",
-		"style":        "积极、温暖且专业",
+		"policy":       characterPrompt.Policy,
+		"persona":      characterPrompt.Persona,
 		"question":     content,     // 使用用户输入内容
 		"chat_history": chatHistory, // 使用实际对话历史
 	})
 	if err != nil {
-		log.Fatalf("format template failed: %v\n", err)
+		return nil, fmt.Errorf("format template failed: %w", err)
 	}
-	return messages
+	return messages, nil
 }
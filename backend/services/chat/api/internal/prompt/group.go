@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const speakerInstruction = `你是群聊主持人。用户正在和多个角色进行群聊，请根据最近的对话判断下一位最适合回复的角色。
+要求：
+1. 优先选择被用户提问或点到的角色，其次选择与话题最相关、还没有表达观点的角色。
+2. 只能从给出的角色名单中选择。
+3. 只输出角色名字本身，不要添加解释。`
+
+// ApplyGroup 在群聊中为发言角色追加群聊规则，members 为群聊中所有角色的名称（含自己）
+func ApplyGroup(p *CharacterPrompt, members map[int64]string) {
+	others := make([]string, 0, len(members))
+	for id, name := range members {
+		if id != p.CharacterID {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+
+	p.Members = members
+	p.Persona += fmt.Sprintf("\n\n这是一个群聊，参与者有用户和以下角色：%s。"+
+		"你只扮演%s，只以%s的身份发言，不要替其他角色说话。其他角色的发言会以“名字：内容”的形式出现，"+
+		"你可以回应或反驳他们的观点。回复时不要在开头加自己的名字。",
+		strings.Join(others, "、"), p.CharacterName, p.CharacterName)
+}
+
+// SpeakerContent 把其他角色的发言标注上名字，作为用户侧消息发给当前角色
+func SpeakerContent(name, content string) string {
+	return name + "：" + content
+}
+
+// BuildSpeakerMessages 构造选择下一位发言角色的请求，transcript 为最近的群聊记录
+func BuildSpeakerMessages(names []string, transcript string) []*schema.Message {
+	return []*schema.Message{
+		schema.SystemMessage(speakerInstruction),
+		schema.UserMessage(fmt.Sprintf("角色名单：%s\n\n最近的对话：\n%s", strings.Join(names, "、"), transcript)),
+	}
+}
+
+// ParseSpeaker 从模型输出中找出被选中的角色，返回其在名单中的下标；多个名字同时出现时取最先出现的
+func ParseSpeaker(output string, names []string) (int, bool) {
+	best, bestAt := -1, -1
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		at := strings.Index(output, name)
+		if at < 0 {
+			continue
+		}
+		if bestAt < 0 || at < bestAt || (at == bestAt && len(name) > len(names[best])) {
+			best, bestAt = i, at
+		}
+	}
+	return best, best >= 0
+}
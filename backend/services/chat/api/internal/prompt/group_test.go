@@ -0,0 +1,23 @@
+package prompt
+
+import "testing"
+
+func TestParseSpeaker(t *testing.T) {
+	names := []string{"苏格拉底", "爱因斯坦", "张三", "张三丰"}
+	cases := []struct {
+		output string
+		want   int
+		ok     bool
+	}{
+		{"爱因斯坦", 1, true},
+		{"下一位：苏格拉底。", 0, true},
+		{"张三丰", 3, true},
+		{"都可以", -1, false},
+	}
+	for _, c := range cases {
+		got, ok := ParseSpeaker(c.output, names)
+		if got != c.want || ok != c.ok {
+			t.Errorf("ParseSpeaker(%q) = %d, %v, want %d, %v", c.output, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-roleplay/common/knowledge"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// KnowledgeMessage 把检索到的角色资料包装为带编号的系统消息，编号与消息元数据中的引用一致
+func KnowledgeMessage(hits []knowledge.Hit) *schema.Message {
+	var content strings.Builder
+	content.WriteString("以下是与用户问题相关的角色背景资料。回答涉及资料中的事实时以资料为准，并在句末用 [编号] 标注出处；资料没有提到的内容不要编造：")
+	for i, hit := range hits {
+		content.WriteString(fmt.Sprintf("\n\n[%d] 《%s》\n%s", i+1, hit.DocumentTitle, hit.Content))
+	}
+	return schema.SystemMessage(content.String())
+}
@@ -0,0 +1,69 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const memoryExtractInstruction = `你是记忆整理助手。请从下面这轮对话中提取关于用户的、值得长期记住的事实，
+例如姓名、职业、学业、兴趣爱好、重要经历、偏好和计划。
+要求：
+1. 只提取用户明确表达的信息，不要推测，不要记录角色说的话。
+2. 已经记住的事实不要重复提取；与已有事实冲突时以新信息为准并重新提取，沿用原来的 key。
+3. 每条事实用一句简短的陈述句描述，例如"用户叫小李，在读物理专业"。
+4. key 是事实的主题，用简短的名词表示，例如"姓名"、"专业"、"喜欢的食物"，同一主题只保留最新的一条。
+5. importance 为 1-5 的整数，越重要越大。
+6. 只输出JSON数组，例如 [{"key":"姓名","content":"用户叫小李","importance":5}]，没有可提取的内容时输出 []。`
+
+// ExtractedMemory 模型提取出的一条记忆
+type ExtractedMemory struct {
+	Key        string `json:"key"` // 事实主题，同一主题的新事实替换旧事实
+	Content    string `json:"content"`
+	Importance int32  `json:"importance"`
+}
+
+// BuildMemoryExtractMessages 构造记忆提取请求，known 为已有事实，带主题时形如“[姓名] 用户叫小李”
+func BuildMemoryExtractMessages(known []string, userContent, reply string) []*schema.Message {
+	var input strings.Builder
+	if len(known) > 0 {
+		input.WriteString("已经记住的事实：\n")
+		for _, fact := range known {
+			input.WriteString(fmt.Sprintf("- %s\n", fact))
+		}
+		input.WriteString("\n")
+	}
+	input.WriteString(fmt.Sprintf("用户：%s\n角色：%s", userContent, reply))
+
+	return []*schema.Message{
+		schema.SystemMessage(memoryExtractInstruction),
+		schema.UserMessage(input.String()),
+	}
+}
+
+// ParseExtractedMemories 解析模型输出，容忍JSON前后的多余文字
+func ParseExtractedMemories(output string) ([]ExtractedMemory, error) {
+	start := strings.Index(output, "[")
+	end := strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no json array in output")
+	}
+
+	var memories []ExtractedMemory
+	if err := json.Unmarshal([]byte(output[start:end+1]), &memories); err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+// MemoryMessage 把角色记得的用户事实包装为系统消息
+func MemoryMessage(facts []string) *schema.Message {
+	var content strings.Builder
+	content.WriteString("以下是你在以往对话中记住的关于用户的信息，请自然地运用，不要逐条复述：")
+	for _, fact := range facts {
+		content.WriteString(fmt.Sprintf("\n- %s", fact))
+	}
+	return schema.SystemMessage(content.String())
+}
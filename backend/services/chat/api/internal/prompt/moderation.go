@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const moderationInstruction = `你是内容安全审核员。请判断下面这段文字是否包含违法、暴力、色情、歧视、自我伤害或危害他人的内容。
+要求：
+1. 角色扮演中正常的冲突、悬疑和历史描写不算违规。
+2. 只输出JSON，例如 {"safe":false,"category":"暴力","reason":"教唆伤害他人"}，没有问题时输出 {"safe":true}。`
+
+// Classification 模型对一段内容的审核结果
+type Classification struct {
+	Safe     bool   `json:"safe"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+}
+
+// BuildModerationMessages 构造内容审核请求
+func BuildModerationMessages(text string) []*schema.Message {
+	return []*schema.Message{
+		schema.SystemMessage(moderationInstruction),
+		schema.UserMessage(text),
+	}
+}
+
+// ParseClassification 解析模型输出，容忍JSON前后的多余文字
+func ParseClassification(output string) (*Classification, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no json object in output")
+	}
+
+	var result Classification
+	if err := json.Unmarshal([]byte(output[start:end+1]), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
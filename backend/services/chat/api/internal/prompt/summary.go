@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const summaryInstruction = `你是对话记录整理助手。请把角色扮演对话中较早的内容压缩成一段摘要，供角色在后续对话中回忆。
+要求：
+1. 保留用户透露的个人信息、偏好、目标，以及双方达成的约定和重要情节。
+2. 保留尚未解决的问题和角色做出的承诺。
+3. 使用第三人称陈述，不要编造对话中没有的内容，不超过300字。
+4. 只输出摘要正文，不要添加标题或解释。`
+
+// BuildSummaryMessages 构造摘要请求，已有摘要时在其基础上合并新消息
+func BuildSummaryMessages(previousSummary string, messages []model.Message) []*schema.Message {
+	var transcript strings.Builder
+	if previousSummary != "" {
+		transcript.WriteString(fmt.Sprintf("已有摘要：\n%s\n\n", previousSummary))
+	}
+	transcript.WriteString("需要合并进摘要的新对话：\n")
+	for _, message := range messages {
+		speaker := "角色"
+		if message.Type == common.AI_Role_User {
+			speaker = "用户"
+		}
+		transcript.WriteString(fmt.Sprintf("%s：%s\n", speaker, message.Content))
+	}
+
+	return []*schema.Message{
+		schema.SystemMessage(summaryInstruction),
+		schema.UserMessage(transcript.String()),
+	}
+}
+
+// SummaryMessage 把摘要包装为系统消息，放在对话历史之前
+func SummaryMessage(summary string) *schema.Message {
+	return schema.SystemMessage(fmt.Sprintf("以下是你们此前对话的摘要，请在回答时参考：\n%s", summary))
+}
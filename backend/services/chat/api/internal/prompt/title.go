@@ -0,0 +1,46 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxTitleLength 自动生成标题的最大字符数
+const maxTitleLength = 20
+
+const titleInstruction = `你是对话标题生成助手。请根据用户和角色的第一轮对话，为这段对话起一个简短的标题。
+要求：
+1. 概括用户关心的话题，不超过15个字。
+2. 不要使用引号、书名号、句末标点和表情符号。
+3. 只输出标题本身，不要添加解释。`
+
+// BuildTitleMessages 构造标题生成请求
+func BuildTitleMessages(userContent, reply string) []*schema.Message {
+	return []*schema.Message{
+		schema.SystemMessage(titleInstruction),
+		schema.UserMessage(fmt.Sprintf("用户：%s\n角色：%s", userContent, reply)),
+	}
+}
+
+// CleanTitle 清理模型输出的标题：取第一行，去掉前缀、引号和首尾标点，超长时截断
+func CleanTitle(output string) string {
+	title := strings.TrimSpace(output)
+	if i := strings.IndexAny(title, "\r\n"); i >= 0 {
+		title = title[:i]
+	}
+	for _, prefix := range []string{"标题：", "标题:"} {
+		title = strings.TrimPrefix(title, prefix)
+	}
+	title = strings.TrimFunc(title, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+
+	runes := []rune(title)
+	if len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength])
+	}
+	return title
+}
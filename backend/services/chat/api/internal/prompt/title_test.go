@@ -0,0 +1,18 @@
+package prompt
+
+import "testing"
+
+func TestCleanTitle(t *testing.T) {
+	cases := map[string]string{
+		"考研复习计划": "考研复习计划",
+		"  「考研复习计划」。\n说明：概括了话题": "考研复习计划",
+		"标题：\"周末去哪儿玩\"":         "周末去哪儿玩",
+		"！！！":                   "",
+		"这是一个非常非常长的标题内容需要被截断到二十个字以内才行": "这是一个非常非常长的标题内容需要被截断到",
+	}
+	for input, want := range cases {
+		if got := CleanTitle(input); got != want {
+			t.Errorf("CleanTitle(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ai-roleplay/services/chat/model"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// usageKey 用户在一个周期内的token用量计数，参数为周期类型、用户ID和周期
+const usageKey = "chat:quota:%s:%d:%s"
+
+// Manager 在 Redis 中实时累计用户的token用量并同步到 MySQL，Redis 中没有计数时从 MySQL 恢复
+type Manager struct {
+	db    *gorm.DB
+	redis *redis.Client
+	conf  Config
+	plans map[string]Plan
+}
+
+func NewManager(db *gorm.DB, rdb *redis.Client, c Config) *Manager {
+	plans := make(map[string]Plan, len(c.Plans))
+	for _, plan := range c.Plans {
+		plans[plan.Name] = plan
+	}
+	return &Manager{
+		db:    db,
+		redis: rdb,
+		conf:  c,
+		plans: plans,
+	}
+}
+
+// Get 返回用户当前的套餐、用量和额度
+func (m *Manager) Get(ctx context.Context, userID int64) (*Usage, error) {
+	now := time.Now()
+	plan, err := m.plan(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	dailyUsed, err := m.used(ctx, userID, PeriodDay, now)
+	if err != nil {
+		return nil, err
+	}
+	monthlyUsed, err := m.used(ctx, userID, PeriodMonth, now)
+	if err != nil {
+		return nil, err
+	}
+	return NewUsage(plan, dailyUsed, monthlyUsed, now), nil
+}
+
+// Consume 按一轮回复的实际用量扣减额度：先累加 Redis 计数，再写入 MySQL
+func (m *Manager) Consume(ctx context.Context, userID, tokens int64) error {
+	if tokens <= 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, periodType := range []string{PeriodDay, PeriodMonth} {
+		// 先确保计数已从 MySQL 恢复，避免只记录本次用量
+		if _, err := m.used(ctx, userID, periodType, now); err != nil {
+			return err
+		}
+		period := PeriodOf(periodType, now)
+		key := fmt.Sprintf(usageKey, periodType, userID, period)
+		pipe := m.redis.TxPipeline()
+		pipe.IncrBy(ctx, key, tokens)
+		pipe.ExpireAt(ctx, key, NextReset(periodType, now).Add(time.Hour))
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+
+		usage := &model.UserTokenUsage{
+			UserID:     userID,
+			PeriodType: periodType,
+			Period:     period,
+			TokensUsed: tokens,
+		}
+		if err := m.db.WithContext(ctx).Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"tokens_used": gorm.Expr("tokens_used + ?", tokens),
+			}),
+		}).Create(usage).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plan 返回用户当前有效的套餐，没有记录、已到期或套餐未配置时使用默认套餐
+func (m *Manager) plan(ctx context.Context, userID int64, now time.Time) (Plan, error) {
+	name := m.conf.DefaultPlan
+	var userPlan model.UserPlan
+	err := m.db.WithContext(ctx).Where("user_id = ?", userID).First(&userPlan).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return Plan{}, err
+	}
+	if err == nil && (userPlan.ExpiresAt == nil || userPlan.ExpiresAt.After(now)) {
+		if _, ok := m.plans[userPlan.Plan]; ok {
+			name = userPlan.Plan
+		}
+	}
+
+	plan, ok := m.plans[name]
+	if !ok {
+		// 默认套餐也未配置时不限额度
+		return Plan{Name: name}, nil
+	}
+	return plan, nil
+}
+
+// used 读取周期内的用量，Redis 中没有计数时从 MySQL 恢复
+func (m *Manager) used(ctx context.Context, userID int64, periodType string, now time.Time) (int64, error) {
+	period := PeriodOf(periodType, now)
+	key := fmt.Sprintf(usageKey, periodType, userID, period)
+	value, err := m.redis.Get(ctx, key).Int64()
+	if err == nil {
+		return value, nil
+	}
+	if err != redis.Nil {
+		return 0, err
+	}
+
+	var usage model.UserTokenUsage
+	err = m.db.WithContext(ctx).Where("user_id = ? AND period_type = ? AND period = ?", userID, periodType, period).
+		First(&usage).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+	// 多个实例同时恢复时只有第一个写入生效
+	if err := m.redis.SetNX(ctx, key, usage.TokensUsed, time.Until(NextReset(periodType, now).Add(time.Hour))).Err(); err != nil {
+		return 0, err
+	}
+	return usage.TokensUsed, nil
+}
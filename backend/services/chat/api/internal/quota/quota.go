@@ -0,0 +1,96 @@
+package quota
+
+import (
+	"time"
+)
+
+// 额度周期
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Config 套餐额度配置
+type Config struct {
+	Enable      bool   `json:",default=true"`
+	DefaultPlan string `json:",default=free"` // 没有套餐记录或套餐到期的用户使用的套餐
+	Plans       []Plan `json:",optional"`
+}
+
+// Plan 套餐及其token额度，0 表示不限
+type Plan struct {
+	Name          string
+	DailyTokens   int64 `json:",optional"`
+	MonthlyTokens int64 `json:",optional"`
+}
+
+// PeriodUsage 一个周期内的用量和额度
+type PeriodUsage struct {
+	Used    int64
+	Limit   int64 // 0 表示不限
+	ResetAt time.Time
+}
+
+// Remaining 剩余额度，不限时返回 -1
+func (p PeriodUsage) Remaining() int64 {
+	if p.Limit <= 0 {
+		return -1
+	}
+	return max(p.Limit-p.Used, 0)
+}
+
+// Exceeded 额度是否已用完
+func (p PeriodUsage) Exceeded() bool {
+	return p.Limit > 0 && p.Used >= p.Limit
+}
+
+// Usage 用户当前的套餐和各周期用量
+type Usage struct {
+	Plan    string
+	Daily   PeriodUsage
+	Monthly PeriodUsage
+}
+
+// NewUsage 按套餐额度和已用量组装当前用量
+func NewUsage(plan Plan, dailyUsed, monthlyUsed int64, now time.Time) *Usage {
+	return &Usage{
+		Plan: plan.Name,
+		Daily: PeriodUsage{
+			Used:    dailyUsed,
+			Limit:   plan.DailyTokens,
+			ResetAt: NextReset(PeriodDay, now),
+		},
+		Monthly: PeriodUsage{
+			Used:    monthlyUsed,
+			Limit:   plan.MonthlyTokens,
+			ResetAt: NextReset(PeriodMonth, now),
+		},
+	}
+}
+
+// Exceeded 额度用完时返回最晚重置的周期：日额度和月额度都用完时要等到月额度重置
+func (u *Usage) Exceeded() (*PeriodUsage, bool) {
+	if u.Monthly.Exceeded() {
+		return &u.Monthly, true
+	}
+	if u.Daily.Exceeded() {
+		return &u.Daily, true
+	}
+	return nil, false
+}
+
+// PeriodOf 返回时间所在的周期，如 2026-10-17、2026-10
+func PeriodOf(periodType string, t time.Time) string {
+	if periodType == PeriodMonth {
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// NextReset 返回下一个周期的开始时间
+func NextReset(periodType string, t time.Time) time.Time {
+	if periodType == PeriodMonth {
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
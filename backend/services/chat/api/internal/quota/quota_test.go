@@ -0,0 +1,51 @@
+package quota
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriods(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 30, 0, 0, time.Local)
+	if got := PeriodOf(PeriodDay, now); got != "2026-12-31" {
+		t.Errorf("day period = %s", got)
+	}
+	if got := PeriodOf(PeriodMonth, now); got != "2026-12" {
+		t.Errorf("month period = %s", got)
+	}
+	if got, want := NextReset(PeriodDay, now), time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("day reset = %v, want %v", got, want)
+	}
+	if got, want := NextReset(PeriodMonth, now), time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("month reset = %v, want %v", got, want)
+	}
+}
+
+func TestUsageExceeded(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	plan := Plan{Name: "free", DailyTokens: 1000, MonthlyTokens: 5000}
+
+	usage := NewUsage(plan, 400, 2000, now)
+	if _, exceeded := usage.Exceeded(); exceeded {
+		t.Error("should not exceed")
+	}
+	if got := usage.Daily.Remaining(); got != 600 {
+		t.Errorf("daily remaining = %d, want 600", got)
+	}
+
+	usage = NewUsage(plan, 1200, 2000, now)
+	period, exceeded := usage.Exceeded()
+	if !exceeded || !period.ResetAt.Equal(usage.Daily.ResetAt) || usage.Daily.Remaining() != 0 {
+		t.Errorf("expected daily exceeded, got %+v", period)
+	}
+
+	usage = NewUsage(plan, 1200, 5000, now)
+	if period, _ := usage.Exceeded(); period == nil || !period.ResetAt.Equal(usage.Monthly.ResetAt) {
+		t.Errorf("expected monthly reset when both exceeded, got %+v", period)
+	}
+
+	usage = NewUsage(Plan{Name: "unlimited"}, 1e9, 1e9, now)
+	if _, exceeded := usage.Exceeded(); exceeded || usage.Daily.Remaining() != -1 {
+		t.Error("unlimited plan should never exceed")
+	}
+}
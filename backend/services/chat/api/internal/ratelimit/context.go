@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// WithClientIP 在 context 中记录请求方IP，供按IP限流使用
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP 读取请求方IP，未记录时返回空
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// TrustedProxies 可信的反向代理网段，只采信这些代理添加的 X-Forwarded-For
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies 解析代理网段，支持 CIDR 和单个IP
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("可信代理网段无效: %s", value)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("可信代理地址无效: %s", value)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return proxies, nil
+}
+
+// Contains 判断地址是否属于可信代理
+func (p TrustedProxies) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestIP 返回请求方IP。直连地址不是可信代理时直接使用直连地址，
+// 否则从右向左跳过可信代理，取 X-Forwarded-For 中第一个不可信的地址，客户端自行填写的左侧地址不会被采信
+func RequestIP(r *http.Request, proxies TrustedProxies) string {
+	ip := stripPort(r.RemoteAddr)
+	if !proxies.Contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := stripPort(strings.TrimSpace(hops[i]))
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		ip = hop
+		if !proxies.Contains(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		remote, forwarded, want string
+	}{
+		{"10.0.0.1:52344", "", "10.0.0.1"},
+		{"[::1]:8080", "", "::1"},
+		{"10.0.0.1:52344", "203.0.113.7", "203.0.113.7"},
+		// 客户端伪造的左侧地址不被采信，取最右侧不可信的一跳
+		{"10.0.0.1:52344", "1.2.3.4, 203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{"[::1]:8080", "203.0.113.7:4711", "203.0.113.7"},
+		// 全部为可信代理时取最左侧
+		{"10.0.0.1:52344", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		// 无效地址之前的一跳
+		{"10.0.0.1:52344", "unknown, 10.0.0.2", "10.0.0.2"},
+		// 直连地址不是可信代理时忽略 X-Forwarded-For
+		{"198.51.100.9:52344", "203.0.113.7", "198.51.100.9"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/api/chat/send", nil)
+		r.RemoteAddr = c.remote
+		if c.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if got := RequestIP(r, proxies); got != c.want {
+			t.Errorf("RequestIP(%q, %q) = %q, want %q", c.remote, c.forwarded, got, c.want)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/api/chat/send", nil)
+	r.RemoteAddr = "10.0.0.1:52344"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	if got := RequestIP(r, nil); got != "10.0.0.1" {
+		t.Errorf("RequestIP without trusted proxies = %q, want remote address", got)
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	for _, value := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := ParseTrustedProxies([]string{value}); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) should fail", value)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenBucketScript 按经过的时间补充令牌后尝试取一个，返回 {是否允许, 需要等待的毫秒数}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// acquireScript 清理过期名额后尝试占用一个，返回 {是否成功, 最早释放的名额还需多少毫秒}
+var acquireScript = redis.NewScript(`
+local limit = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local expireAt = tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= limit then
+  local first = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+  return {0, tonumber(first[2]) - now}
+end
+redis.call('ZADD', KEYS[1], expireAt, ARGV[1])
+redis.call('PEXPIRE', KEYS[1], expireAt - now)
+return {1, 0}
+`)
+
+// Bucket 令牌桶参数
+type Bucket struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 桶容量，即允许的突发请求数
+}
+
+// Limiter 基于 Redis 的令牌桶限流和并发数限制，多个实例共享同一份计数
+type Limiter struct {
+	redis *redis.Client
+}
+
+func NewLimiter(rdb *redis.Client) *Limiter {
+	return &Limiter{redis: rdb}
+}
+
+// Allow 从 key 对应的令牌桶取一个令牌，令牌不足时返回需要等待的时间
+func (l *Limiter) Allow(ctx context.Context, key string, bucket Bucket) (bool, time.Duration, error) {
+	if bucket.Rate <= 0 || bucket.Burst <= 0 {
+		return true, 0, nil
+	}
+	result, err := tokenBucketScript.Run(ctx, l.redis, []string{key},
+		bucket.Rate, bucket.Burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Acquire 在 key 下占用一个名额，已有 limit 个时返回 false 和最早释放的名额还需等待的时间；
+// 名额在 ttl 后自动失效，避免实例异常退出后名额无法释放
+func (l *Limiter) Acquire(ctx context.Context, key, member string, limit int, ttl time.Duration) (bool, time.Duration, error) {
+	if limit <= 0 {
+		return true, 0, nil
+	}
+	now := time.Now().UnixMilli()
+	result, err := acquireScript.Run(ctx, l.redis, []string{key},
+		member, limit, now, now+ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Release 释放占用的名额
+func (l *Limiter) Release(ctx context.Context, key, member string) error {
+	return l.redis.ZRem(ctx, key, member).Err()
+}
@@ -2,15 +2,17 @@ package repo
 
 import (
 	common "ai-roleplay/common/utils"
+	characterModel "ai-roleplay/services/character/model"
+	"ai-roleplay/services/chat/api/internal/search"
 	"ai-roleplay/services/chat/api/internal/svc"
 	"ai-roleplay/services/chat/api/internal/types"
 	"ai-roleplay/services/chat/model"
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChatServiceRepo struct {
@@ -39,11 +41,17 @@ func (r *ChatServiceRepo) SendMessage(message *model.Message) error {
 	return nil
 }
 
-// CreateConversation 创建对话
+// CreateConversation 创建对话，同时累加角色的对话数
 func (r *ChatServiceRepo) CreateConversation(conversation *model.Conversation) error {
 	db := r.svcCtx.Db.WithContext(r.ctx)
 
-	if err := db.Create(conversation).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		return incrChatCount(tx, conversation.CharacterID)
+	})
+	if err != nil {
 		r.Logger.Error("CreateConversation failed: ", err)
 		return err
 	}
@@ -51,6 +59,38 @@ func (r *ChatServiceRepo) CreateConversation(conversation *model.Conversation) e
 	return nil
 }
 
+// StartConversation 在一个事务中创建对话、保存第一条消息并累加角色的对话数，
+// 任一步失败时都不会留下没有消息的空对话
+func (r *ChatServiceRepo) StartConversation(conversation *model.Conversation, message *model.Message) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		message.ConversationID = conversation.ID
+		if err := addBranchMessage(tx, message); err != nil {
+			return err
+		}
+		return incrChatCount(tx, conversation.CharacterID)
+	})
+	if err != nil {
+		r.Logger.Error("StartConversation failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// incrChatCount 累加角色的对话数，未指定角色时跳过
+func incrChatCount(tx *gorm.DB, characterID int64) error {
+	if characterID <= 0 {
+		return nil
+	}
+	return tx.Model(&characterModel.Character{}).Where("id = ?", characterID).
+		UpdateColumn("chat_count", gorm.Expr("chat_count + 1")).Error
+}
+
 // GetConversationByID 根据ID获取对话
 func (r *ChatServiceRepo) GetConversationByID(id int64) (*model.Conversation, error) {
 	db := r.svcCtx.Db.WithContext(r.ctx)
@@ -115,41 +155,6 @@ func (r *ChatServiceRepo) GetConversationList(req *types.ConversationListRequest
 	return conversations, total, nil
 }
 
-// GetMessages 获取对话消息列表
-func (r *ChatServiceRepo) GetMessages(req *types.MessageListRequest) ([]model.Message, int64, error) {
-	db := r.svcCtx.Db.WithContext(r.ctx)
-
-	// 设置默认值
-	page := req.Page
-	if page <= 0 {
-		page = 1
-	}
-	pageSize := req.PageSize
-	if pageSize <= 0 {
-		pageSize = 50
-	}
-	offset := (page - 1) * pageSize
-
-	// 构建查询条件
-	query := db.Model(&model.Message{}).Where("conversation_id = ?", req.ConversationID)
-
-	// 统计总数
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		r.Logger.Error("GetMessages count failed: ", err)
-		return nil, 0, err
-	}
-
-	// 查询数据，按时间正序
-	var messages []model.Message
-	if err := query.Order("created_at ASC").Offset(offset).Limit(pageSize).Find(&messages).Error; err != nil {
-		r.Logger.Error("GetMessages find failed: ", err)
-		return nil, 0, err
-	}
-
-	return messages, total, nil
-}
-
 // DeleteConversation 删除对话（软删除）
 func (r *ChatServiceRepo) DeleteConversation(id int64) error {
 	db := r.svcCtx.Db.WithContext(r.ctx)
@@ -180,8 +185,12 @@ func (r *ChatServiceRepo) ClearMessages(conversationID int64) error {
 func (r *ChatServiceRepo) UpdateConversationTitle(id int64, title string) error {
 	db := r.svcCtx.Db.WithContext(r.ctx)
 
+	// 用户设置的标题不再被自动生成覆盖
 	if err := db.Model(&model.Conversation{}).Where("id = ?", id).
-		Update("title", title).Error; err != nil {
+		Updates(map[string]interface{}{
+			"title":            title,
+			"title_customized": 1,
+		}).Error; err != nil {
 		r.Logger.Error("UpdateConversationTitle failed: ", err)
 		return err
 	}
@@ -189,6 +198,20 @@ func (r *ChatServiceRepo) UpdateConversationTitle(id int64, title string) error
 	return nil
 }
 
+// UpdateAutoTitle 写入自动生成的标题，用户已设置过标题时不更新，返回是否已更新
+func (r *ChatServiceRepo) UpdateAutoTitle(id int64, title string) (bool, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	result := db.Model(&model.Conversation{}).Where("id = ? AND title_customized = 0", id).
+		Update("title", title)
+	if result.Error != nil {
+		r.Logger.Error("UpdateAutoTitle failed: ", result.Error)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
 // SearchConversations 搜索对话
 func (r *ChatServiceRepo) SearchConversations(req *types.SearchConversationRequest) ([]model.Conversation, int64, error) {
 	db := r.svcCtx.Db.WithContext(r.ctx)
@@ -210,8 +233,7 @@ func (r *ChatServiceRepo) SearchConversations(req *types.SearchConversationReque
 	query = query.Where("status != ?", common.Deleted)
 
 	if req.Keyword != "" {
-		keyword := "%" + req.Keyword + "%"
-		query = query.Where("title LIKE ?", keyword)
+		query = r.matchKeyword(query, req.Keyword)
 	}
 
 	// 用户筛选
@@ -219,6 +241,14 @@ func (r *ChatServiceRepo) SearchConversations(req *types.SearchConversationReque
 		query = query.Where("user_id = ?", req.UserID)
 	}
 
+	if req.StartTime != "" {
+		query = query.Where("created_at >= ?", req.StartTime)
+	}
+
+	if req.EndTime != "" {
+		query = query.Where("created_at <= ?", req.EndTime)
+	}
+
 	// 统计总数
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -244,16 +274,16 @@ func (r *ChatServiceRepo) ExportConversation(conversationID int64) (*model.Conve
 	var conversation model.Conversation
 	if err := db.Where("id = ?", conversationID).First(&conversation).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, nil, fmt.Errorf("conversation not found")
+			return nil, nil, nil
 		}
 		r.Logger.Error("ExportConversation get conversation failed: ", err)
 		return nil, nil, err
 	}
 
-	// 获取所有消息
+	// 获取所有消息，包括未选中的分支
 	var messages []model.Message
 	if err := db.Where("conversation_id = ?", conversationID).
-		Order("created_at ASC").Find(&messages).Error; err != nil {
+		Order("created_at ASC, id ASC").Find(&messages).Error; err != nil {
 		r.Logger.Error("ExportConversation get messages failed: ", err)
 		return nil, nil, err
 	}
@@ -261,6 +291,18 @@ func (r *ChatServiceRepo) ExportConversation(conversationID int64) (*model.Conve
 	return &conversation, messages, nil
 }
 
+// GetAudioFiles 批量获取语音文件
+func (r *ChatServiceRepo) GetAudioFiles(ids []int64) ([]model.AudioFile, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var audioFiles []model.AudioFile
+	if err := db.Where("id IN ?", ids).Find(&audioFiles).Error; err != nil {
+		r.Logger.Error("GetAudioFiles failed: ", err)
+		return nil, err
+	}
+	return audioFiles, nil
+}
+
 // BatchDeleteConversations 批量删除对话（软删除）
 func (r *ChatServiceRepo) BatchDeleteConversations(conversationIDs []int64) error {
 	db := r.svcCtx.Db.WithContext(r.ctx)
@@ -393,14 +435,17 @@ func (r *ChatServiceRepo) GetConversationHistory(req *types.GetConversationHisto
 	query = query.Where("status != ?", common.Deleted)
 
 	if req.Keyword != "" {
-		keyword := "%" + req.Keyword + "%"
-		query = query.Where("title LIKE ?", keyword)
+		query = r.matchKeyword(query, req.Keyword)
 	}
 
 	if req.UserID > 0 {
 		query = query.Where("user_id = ?", req.UserID)
 	}
 
+	if req.CharacterID > 0 {
+		query = query.Where("character_id = ?", req.CharacterID)
+	}
+
 	if req.StartTime != "" {
 		query = query.Where("created_at >= ?", req.StartTime)
 	}
@@ -464,7 +509,8 @@ func (r *ChatServiceRepo) GetConversationMessages(conversationID int64) ([]model
 	db := r.svcCtx.Db.WithContext(r.ctx)
 
 	var messages []model.Message
-	if err := db.Where("conversation_id = ?", conversationID).Find(&messages).Error; err != nil {
+	if err := db.Where("conversation_id = ?", conversationID).
+		Order("created_at ASC, id ASC").Find(&messages).Error; err != nil {
 		r.Logger.Error("GetConversationMessages failed: ", err)
 		return nil, err
 	}
@@ -472,12 +518,74 @@ func (r *ChatServiceRepo) GetConversationMessages(conversationID int64) ([]model
 	return messages, nil
 }
 
-func (r *ChatServiceRepo) AddMessage(message *model.Message) (int64, error) {
+// activePathCTE 递归查询当前选中的分支路径：从选中的首条消息开始，逐层只沿选中的子消息向下，
+// 未选中分支的后代即使自身 is_active=1 也不会进入路径。所有按当前分支读取消息的地方都以此为准
+const activePathCTE = `WITH RECURSIVE active_path AS (
+		SELECT * FROM messages WHERE conversation_id = ? AND parent_id IS NULL AND is_active = 1
+		UNION ALL
+		SELECT m.* FROM messages m JOIN active_path p ON m.parent_id = p.id
+		WHERE m.conversation_id = ? AND m.is_active = 1
+	) `
+
+// GetActivePathPage 分页获取当前分支路径上的消息，分页和计数都在数据库中完成
+func (r *ChatServiceRepo) GetActivePathPage(conversationID int64, page, pageSize int) ([]model.Message, int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var total int64
+	if err := db.Raw(activePathCTE+"SELECT COUNT(*) FROM active_path", conversationID, conversationID).
+		Scan(&total).Error; err != nil {
+		r.Logger.Error("GetActivePathPage count failed: ", err)
+		return nil, 0, err
+	}
+
+	var messages []model.Message
+	if err := db.Raw(activePathCTE+"SELECT * FROM active_path ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?",
+		conversationID, conversationID, pageSize, (page-1)*pageSize).Scan(&messages).Error; err != nil {
+		r.Logger.Error("GetActivePathPage failed: ", err)
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// GetSiblingMessages 获取指定父消息下的全部分支，只查询分组需要的字段；includeRoot 时包含对话的首条消息
+func (r *ChatServiceRepo) GetSiblingMessages(conversationID int64, parentIDs []int64, includeRoot bool) ([]model.Message, error) {
 	db := r.svcCtx.Db.WithContext(r.ctx)
 
-	if message.Type != common.AI_Role_User {
-		message.Type = "ai"
+	if len(parentIDs) == 0 && !includeRoot {
+		return nil, nil
+	}
+	query := db.Model(&model.Message{}).Select("id, parent_id, branch").Where("conversation_id = ?", conversationID)
+	switch {
+	case len(parentIDs) > 0 && includeRoot:
+		query = query.Where("(parent_id IN ? OR parent_id IS NULL)", parentIDs)
+	case len(parentIDs) > 0:
+		query = query.Where("parent_id IN ?", parentIDs)
+	default:
+		query = query.Where("parent_id IS NULL")
+	}
+
+	var messages []model.Message
+	if err := query.Find(&messages).Error; err != nil {
+		r.Logger.Error("GetSiblingMessages failed: ", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// storedMessageType 用户消息以外的类型（如 assistant）统一保存为 AI 消息
+func storedMessageType(messageType string) string {
+	if messageType == common.Message_Type_User {
+		return common.Message_Type_User
 	}
+	return common.Message_Type_AI
+}
+
+func (r *ChatServiceRepo) AddMessage(message *model.Message) (int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	message.Type = storedMessageType(message.Type)
 
 	if err := db.Create(message).Error; err != nil {
 		r.Logger.Error("AddMessage failed: ", err)
@@ -486,3 +594,600 @@ func (r *ChatServiceRepo) AddMessage(message *model.Message) (int64, error) {
 
 	return message.ID, nil
 }
+
+// GetCharacterByID 获取对话绑定的角色信息
+func (r *ChatServiceRepo) GetCharacterByID(id int64) (*characterModel.Character, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var character characterModel.Character
+	if err := db.Where("id = ? AND status = ?", id, common.Normal).First(&character).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.Logger.Error("GetCharacterByID failed: ", err)
+		return nil, err
+	}
+
+	return &character, nil
+}
+
+// GetCharactersByIDs 批量获取正常状态的角色
+func (r *ChatServiceRepo) GetCharactersByIDs(ids []int64) ([]characterModel.Character, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var characters []characterModel.Character
+	if err := db.Where("id IN ? AND status = ?", ids, common.Normal).Find(&characters).Error; err != nil {
+		r.Logger.Error("GetCharactersByIDs failed: ", err)
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// CreateGroupConversation 创建群聊并按顺序保存参与角色
+func (r *ChatServiceRepo) CreateGroupConversation(conversation *model.Conversation, characterIDs []int64) ([]model.ConversationParticipant, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	participants := make([]model.ConversationParticipant, 0, len(characterIDs))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		for i, characterID := range characterIDs {
+			participants = append(participants, model.ConversationParticipant{
+				ConversationID: conversation.ID,
+				CharacterID:    characterID,
+				Position:       int32(i),
+				Status:         common.Normal,
+			})
+		}
+		return tx.Create(&participants).Error
+	})
+	if err != nil {
+		r.Logger.Error("CreateGroupConversation failed: ", err)
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+// GetParticipants 获取群聊的参与角色，按轮流顺序排列
+func (r *ChatServiceRepo) GetParticipants(conversationID int64) ([]model.ConversationParticipant, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var participants []model.ConversationParticipant
+	if err := db.Where("conversation_id = ? AND status = ?", conversationID, common.Normal).
+		Order("position ASC, id ASC").Find(&participants).Error; err != nil {
+		r.Logger.Error("GetParticipants failed: ", err)
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+// GetConversationSummary 获取对话摘要，不存在时返回 nil
+func (r *ChatServiceRepo) GetConversationSummary(conversationID int64) (*model.ConversationSummary, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var summary model.ConversationSummary
+	if err := db.Where("conversation_id = ?", conversationID).First(&summary).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.Logger.Error("GetConversationSummary failed: ", err)
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// SaveConversationSummary 保存对话摘要，已存在时覆盖
+func (r *ChatServiceRepo) SaveConversationSummary(summary *model.ConversationSummary) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"summary", "covered_message_id", "message_count", "updated_at"}),
+	}).Create(summary).Error; err != nil {
+		r.Logger.Error("SaveConversationSummary failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteConversationSummary 删除对话摘要
+func (r *ChatServiceRepo) DeleteConversationSummary(conversationID int64) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Where("conversation_id = ?", conversationID).Delete(&model.ConversationSummary{}).Error; err != nil {
+		r.Logger.Error("DeleteConversationSummary failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetMessagesAfter 获取当前分支路径上指定消息之后的消息，按时间正序；afterMessageID 为0时返回完整路径
+func (r *ChatServiceRepo) GetMessagesAfter(conversationID int64, afterMessageID int64) ([]model.Message, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var messages []model.Message
+	if err := db.Raw(activePathCTE+"SELECT * FROM active_path WHERE id > ? ORDER BY created_at ASC, id ASC",
+		conversationID, conversationID, afterMessageID).Scan(&messages).Error; err != nil {
+		r.Logger.Error("GetMessagesAfter failed: ", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// GetLastPathMessage 获取当前分支路径上的最后一条消息，对话没有消息时返回 nil
+func (r *ChatServiceRepo) GetLastPathMessage(conversationID int64) (*model.Message, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var messages []model.Message
+	if err := db.Raw(activePathCTE+"SELECT * FROM active_path ORDER BY created_at DESC, id DESC LIMIT 1",
+		conversationID, conversationID).Scan(&messages).Error; err != nil {
+		r.Logger.Error("GetLastPathMessage failed: ", err)
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	return &messages[0], nil
+}
+
+// AddBranchMessage 把消息保存为父消息下的新分支，并取消同级其他分支的选中状态
+func (r *ChatServiceRepo) AddBranchMessage(message *model.Message) (int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return addBranchMessage(tx, message)
+	})
+	if err != nil {
+		r.Logger.Error("AddBranchMessage failed: ", err)
+		return 0, err
+	}
+
+	return message.ID, nil
+}
+
+// addBranchMessage 在事务中保存分支消息，并更新对话的最后更新时间
+func addBranchMessage(tx *gorm.DB, message *model.Message) error {
+	message.Type = storedMessageType(message.Type)
+
+	var maxBranch int32
+	if err := siblingQuery(tx, message).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("COALESCE(MAX(branch), 0)").Scan(&maxBranch).Error; err != nil {
+		return err
+	}
+	if err := siblingQuery(tx, message).Update("is_active", 0).Error; err != nil {
+		return err
+	}
+
+	message.Branch = maxBranch + 1
+	message.IsActive = 1
+	if err := tx.Create(message).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.Conversation{}).Where("id = ?", message.ConversationID).
+		Update("updated_at", time.Now()).Error
+}
+
+// SetActiveBranch 选中消息所在的分支
+func (r *ChatServiceRepo) SetActiveBranch(message *model.Message) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := siblingQuery(tx, message).Update("is_active", 0).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Message{}).Where("id = ?", message.ID).Update("is_active", 1).Error
+	})
+	if err != nil {
+		r.Logger.Error("SetActiveBranch failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// siblingQuery 查询与消息同属一个父消息的所有分支（含自身）
+func siblingQuery(tx *gorm.DB, message *model.Message) *gorm.DB {
+	query := tx.Model(&model.Message{}).Where("conversation_id = ?", message.ConversationID)
+	if message.ParentID == nil {
+		return query.Where("parent_id IS NULL")
+	}
+	return query.Where("parent_id = ?", *message.ParentID)
+}
+
+// GetMemories 获取用户与角色之间的长期记忆，按重要程度和更新时间倒序
+func (r *ChatServiceRepo) GetMemories(userID, characterID int64) ([]model.UserCharacterMemory, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	query := db.Where("user_id = ? AND status = ?", userID, common.Normal)
+	if characterID > 0 {
+		query = query.Where("character_id = ?", characterID)
+	}
+
+	var memories []model.UserCharacterMemory
+	if err := query.Order("importance DESC, updated_at DESC").Find(&memories).Error; err != nil {
+		r.Logger.Error("GetMemories failed: ", err)
+		return nil, err
+	}
+
+	return memories, nil
+}
+
+// GetMemoryByID 根据ID获取记忆
+func (r *ChatServiceRepo) GetMemoryByID(id int64) (*model.UserCharacterMemory, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var memory model.UserCharacterMemory
+	if err := db.Where("id = ? AND status = ?", id, common.Normal).First(&memory).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.Logger.Error("GetMemoryByID failed: ", err)
+		return nil, err
+	}
+
+	return &memory, nil
+}
+
+// CreateMemories 批量保存新提取的记忆
+func (r *ChatServiceRepo) CreateMemories(memories []*model.UserCharacterMemory) error {
+	if len(memories) == 0 {
+		return nil
+	}
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Create(&memories).Error; err != nil {
+		r.Logger.Error("CreateMemories failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReplaceMemory 用同主题的新事实替换已有记忆的内容、重要程度和来源消息
+func (r *ChatServiceRepo) ReplaceMemory(memory *model.UserCharacterMemory) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Model(&model.UserCharacterMemory{}).Where("id = ?", memory.ID).
+		Updates(map[string]interface{}{
+			"content":           memory.Content,
+			"importance":        memory.Importance,
+			"source_message_id": memory.SourceMessageID,
+		}).Error; err != nil {
+		r.Logger.Error("ReplaceMemory failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateMemory 更新记忆内容和重要程度
+func (r *ChatServiceRepo) UpdateMemory(id, userID int64, content string, importance int32) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Model(&model.UserCharacterMemory{}).Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"content":    content,
+			"importance": importance,
+		}).Error; err != nil {
+		r.Logger.Error("UpdateMemory failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMemory 删除记忆（软删除）
+func (r *ChatServiceRepo) DeleteMemory(id, userID int64) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Model(&model.UserCharacterMemory{}).Where("id = ? AND user_id = ?", id, userID).
+		Update("status", common.Deleted).Error; err != nil {
+		r.Logger.Error("DeleteMemory failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetModeratedMessages 分页获取带有审核结论的消息，按时间倒序；userID 大于0时只查该用户的对话，stage、action 为空时不筛选
+func (r *ChatServiceRepo) GetModeratedMessages(userID int64, stage, action string, page, pageSize int) ([]model.Message, int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	query := db.Model(&model.Message{}).Where("JSON_EXTRACT(metadata, '$.moderation') IS NOT NULL")
+	if userID > 0 {
+		query = query.Where("conversation_id IN (?)", db.Model(&model.Conversation{}).Select("id").Where("user_id = ?", userID))
+	}
+	if stage != "" {
+		query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.moderation.stage')) = ?", stage)
+	}
+	if action != "" {
+		query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.moderation.action')) = ?", action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.Logger.Error("GetModeratedMessages count failed: ", err)
+		return nil, 0, err
+	}
+
+	var messages []model.Message
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&messages).Error; err != nil {
+		r.Logger.Error("GetModeratedMessages find failed: ", err)
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// UsageStat 一个统计周期内AI回复的用量汇总
+type UsageStat struct {
+	Period              string
+	MessageCount        int64
+	TotalTokens         int64
+	TotalProcessingTime int64
+}
+
+// GetUsageStats 按周期汇总用户AI回复的token用量和耗时，conversationID 大于0时只统计该对话
+func (r *ChatServiceRepo) GetUsageStats(userID, conversationID int64, start, end time.Time, periodFormat string) ([]UsageStat, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	query := db.Table("messages m").
+		Select("DATE_FORMAT(m.created_at, ?) AS period, COUNT(*) AS message_count, "+
+			"COALESCE(SUM(m.token_used), 0) AS total_tokens, COALESCE(SUM(m.processing_time), 0) AS total_processing_time", periodFormat).
+		Joins("JOIN conversations c ON c.id = m.conversation_id").
+		Where("c.user_id = ? AND m.type = ?", userID, common.Message_Type_AI).
+		Where("m.created_at >= ? AND m.created_at < ?", start, end)
+	if conversationID > 0 {
+		query = query.Where("m.conversation_id = ?", conversationID)
+	}
+
+	var stats []UsageStat
+	if err := query.Group("period").Order("period").Scan(&stats).Error; err != nil {
+		r.Logger.Error("GetUsageStats failed: ", err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// SaveMessageFeedback 保存用户对消息的反馈，已反馈过时覆盖
+func (r *ChatServiceRepo) SaveMessageFeedback(feedback *model.MessageFeedback) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"vote", "reasons", "comment", "updated_at"}),
+	}).Create(feedback).Error; err != nil {
+		r.Logger.Error("SaveMessageFeedback failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetMessageFeedback 获取用户对消息的反馈
+func (r *ChatServiceRepo) GetMessageFeedback(messageID, userID int64) (*model.MessageFeedback, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var feedback model.MessageFeedback
+	if err := db.Where("message_id = ? AND user_id = ?", messageID, userID).First(&feedback).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.Logger.Error("GetMessageFeedback failed: ", err)
+		return nil, err
+	}
+
+	return &feedback, nil
+}
+
+// DeleteMessageFeedback 撤回用户对消息的反馈
+func (r *ChatServiceRepo) DeleteMessageFeedback(messageID, userID int64) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Where("message_id = ? AND user_id = ?", messageID, userID).
+		Delete(&model.MessageFeedback{}).Error; err != nil {
+		r.Logger.Error("DeleteMessageFeedback failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// SaveCharacterRating 保存用户对角色的评分，已评过时覆盖
+func (r *ChatServiceRepo) SaveCharacterRating(rating *model.CharacterRating) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	if err := db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+	}).Create(rating).Error; err != nil {
+		r.Logger.Error("SaveCharacterRating failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// RefreshCharacterRating 重新汇总角色的评分：每个用户计一票，有星级评分时取评分，
+// 否则按该用户消息反馈中赞的比例折算为1-5分
+func (r *ChatServiceRepo) RefreshCharacterRating(characterID int64) error {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	var result struct {
+		Rating      float64
+		RatingCount int32
+	}
+	if err := db.Raw(`SELECT COALESCE(AVG(s.score), 0) AS rating, COUNT(*) AS rating_count FROM (
+			SELECT cr.score FROM character_ratings cr WHERE cr.character_id = ?
+			UNION ALL
+			SELECT 1 + 4 * SUM(f.vote > 0) / COUNT(*) FROM message_feedback f
+			WHERE f.character_id = ? AND NOT EXISTS (
+				SELECT 1 FROM character_ratings cr WHERE cr.character_id = f.character_id AND cr.user_id = f.user_id)
+			GROUP BY f.user_id
+		) s`, characterID, characterID).Scan(&result).Error; err != nil {
+		r.Logger.Error("RefreshCharacterRating failed: ", err)
+		return err
+	}
+
+	if err := db.Model(&characterModel.Character{}).Where("id = ?", characterID).
+		UpdateColumns(map[string]interface{}{
+			"rating":       result.Rating,
+			"rating_count": result.RatingCount,
+		}).Error; err != nil {
+		r.Logger.Error("RefreshCharacterRating failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// FeedbackExportRow 导出的一条反馈，附带对应的用户问题和AI回复
+type FeedbackExportRow struct {
+	MessageID      int64
+	ConversationID int64
+	CharacterID    *int64
+	CharacterName  *string
+	Vote           int8
+	Reasons        *string
+	Comment        string
+	Question       *string
+	Reply          string
+	Metadata       *string
+	UpdatedAt      time.Time
+}
+
+// GetFeedbackExport 分页导出消息反馈，按时间倒序；userID 大于0时只导出该用户的反馈，vote 为0、characterID 为0时不筛选
+func (r *ChatServiceRepo) GetFeedbackExport(userID int64, vote int8, characterID int64, start, end time.Time, page, pageSize int) ([]FeedbackExportRow, int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	query := db.Table("message_feedback f").
+		Joins("JOIN messages m ON m.id = f.message_id").
+		Joins("LEFT JOIN messages p ON p.id = m.parent_id").
+		Joins("LEFT JOIN characters c ON c.id = f.character_id").
+		Where("f.updated_at >= ? AND f.updated_at < ?", start, end)
+	if userID > 0 {
+		query = query.Where("f.user_id = ?", userID)
+	}
+	if vote != 0 {
+		query = query.Where("f.vote = ?", vote)
+	}
+	if characterID > 0 {
+		query = query.Where("f.character_id = ?", characterID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.Logger.Error("GetFeedbackExport failed: ", err)
+		return nil, 0, err
+	}
+
+	var rows []FeedbackExportRow
+	if err := query.Select("f.message_id, f.conversation_id, f.character_id, c.name AS character_name, f.vote, f.reasons, f.comment, " +
+		"p.content AS question, m.content AS reply, m.metadata, f.updated_at").
+		Order("f.updated_at DESC, f.id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error; err != nil {
+		r.Logger.Error("GetFeedbackExport failed: ", err)
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
+
+// matchKeyword 按关键词筛选对话：标题包含关键词，或有消息内容包含全部关键词
+func (r *ChatServiceRepo) matchKeyword(query *gorm.DB, keyword string) *gorm.DB {
+	terms := search.Terms(keyword)
+	if len(terms) == 0 {
+		return query
+	}
+	messages := matchContent(r.svcCtx.Db.WithContext(r.ctx).Model(&model.Message{}).Select("conversation_id"), "content", terms)
+	return query.Where("(title LIKE ? OR id IN (?))", search.LikePattern(keyword), messages)
+}
+
+// matchContent 按关键词筛选消息内容：优先使用 ngram 全文索引，有关键词过短时改用 LIKE
+func matchContent(query *gorm.DB, column string, terms []string) *gorm.DB {
+	if expr := search.BooleanQuery(terms); expr != "" {
+		return query.Where("MATCH("+column+") AGAINST(? IN BOOLEAN MODE)", expr)
+	}
+	for _, term := range terms {
+		query = query.Where(column+" LIKE ?", search.LikePattern(term))
+	}
+	return query
+}
+
+// MessageSearchRow 一条命中的消息
+type MessageSearchRow struct {
+	MessageID         int64
+	ConversationID    int64
+	ConversationTitle string
+	CharacterID       int64
+	CharacterName     *string
+	Type              string
+	Content           string
+	IsActive          bool
+	CreatedAt         time.Time
+}
+
+// SearchMessages 在用户的对话中检索包含全部关键词的消息，使用全文索引时按相关度排序，否则按时间倒序；
+// characterID 为0、start/end 为零值时不筛选
+func (r *ChatServiceRepo) SearchMessages(userID int64, terms []string, characterID int64, start, end time.Time, page, pageSize int) ([]MessageSearchRow, int64, error) {
+	db := r.svcCtx.Db.WithContext(r.ctx)
+
+	query := db.Table("messages m").
+		Joins("JOIN conversations c ON c.id = m.conversation_id").
+		Joins("LEFT JOIN characters ch ON ch.id = COALESCE(m.character_id, c.character_id)").
+		Where("c.user_id = ? AND c.status != ?", userID, common.Deleted)
+	query = matchContent(query, "m.content", terms)
+	if characterID > 0 {
+		query = query.Where("COALESCE(m.character_id, c.character_id) = ?", characterID)
+	}
+	if !start.IsZero() {
+		query = query.Where("m.created_at >= ?", start)
+	}
+	if !end.IsZero() {
+		query = query.Where("m.created_at < ?", end)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.Logger.Error("SearchMessages failed: ", err)
+		return nil, 0, err
+	}
+
+	if expr := search.BooleanQuery(terms); expr != "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "MATCH(m.content) AGAINST(? IN BOOLEAN MODE) DESC, m.created_at DESC",
+			Vars: []interface{}{expr},
+		}})
+	} else {
+		query = query.Order("m.created_at DESC")
+	}
+
+	var rows []MessageSearchRow
+	if err := query.Select("m.id AS message_id, m.conversation_id, c.title AS conversation_title, " +
+		"COALESCE(m.character_id, c.character_id) AS character_id, ch.name AS character_name, " +
+		"m.type, m.content, m.is_active, m.created_at").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error; err != nil {
+		r.Logger.Error("SearchMessages failed: ", err)
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
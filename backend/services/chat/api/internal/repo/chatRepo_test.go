@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	common "ai-roleplay/common/utils"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/model"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunStatement 最近一次生成的查询语句和参数
+type dryRunStatement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// newDryRunRepo 只生成SQL不执行的仓库，返回最近一次查询的语句，用于校验查询条件
+func newDryRunRepo(t *testing.T) (*ChatServiceRepo, *dryRunStatement) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:@tcp(127.0.0.1:3306)/ai_roleplay?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := &dryRunStatement{}
+	capture := func(tx *gorm.DB) {
+		last.SQL, last.Vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	return NewChatServiceRepo(context.Background(), &svc.ServiceContext{Db: db}), last
+}
+
+func TestUsageStatsCountStoredAIMessages(t *testing.T) {
+	repo, last := newDryRunRepo(t)
+
+	// 生成回复时按模型的角色名 assistant 传入，保存时需统一为 AI 消息类型
+	message := &model.Message{ConversationID: 1, Type: common.AI_Role_Assistant, Content: "你好"}
+	if _, err := repo.AddMessage(message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Type != common.Message_Type_AI {
+		t.Fatalf("stored type %q, want %q", message.Type, common.Message_Type_AI)
+	}
+
+	end := time.Now()
+	// DryRun 不支持读取结果，只校验生成的查询参数
+	if _, err := repo.GetUsageStats(1, 0, end.AddDate(0, 0, -7), end, "%Y-%m-%d"); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatal(err)
+	}
+	found := false
+	for _, v := range last.Vars {
+		if v == message.Type {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("usage stats query does not filter on stored type %q: %v", message.Type, last.Vars)
+	}
+}
+
+func TestModeratedMessagesScopedToUser(t *testing.T) {
+	repo, last := newDryRunRepo(t)
+
+	if _, _, err := repo.GetModeratedMessages(7, "", "", 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(last.SQL, "user_id = ?") || !slices.Contains(last.Vars, interface{}(int64(7))) {
+		t.Fatalf("moderated messages not scoped to user: %s %v", last.SQL, last.Vars)
+	}
+
+	if _, _, err := repo.GetModeratedMessages(0, "", "", 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(last.SQL, "user_id") {
+		t.Fatalf("admin query should not be scoped: %s", last.SQL)
+	}
+}
+
+func TestFeedbackExportScopedToUser(t *testing.T) {
+	repo, last := newDryRunRepo(t)
+	end := time.Now()
+
+	if _, _, err := repo.GetFeedbackExport(7, 0, 0, end.AddDate(0, 0, -30), end, 1, 100); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatal(err)
+	}
+	if !strings.Contains(last.SQL, "f.user_id = ?") || !slices.Contains(last.Vars, interface{}(int64(7))) {
+		t.Fatalf("feedback export not scoped to user: %s %v", last.SQL, last.Vars)
+	}
+
+	if _, _, err := repo.GetFeedbackExport(0, 0, 0, end.AddDate(0, 0, -30), end, 1, 100); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatal(err)
+	}
+	if strings.Contains(last.SQL, "user_id") {
+		t.Fatalf("admin export should not be scoped: %s", last.SQL)
+	}
+}
+
+func TestActivePathQueriesUseCTE(t *testing.T) {
+	repo, last := newDryRunRepo(t)
+
+	if _, err := repo.GetMessagesAfter(5, 42); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(last.SQL, "WITH RECURSIVE active_path") || !strings.Contains(last.SQL, "WHERE id > ?") ||
+		!slices.Equal(last.Vars, []interface{}{int64(5), int64(5), int64(42)}) {
+		t.Fatalf("GetMessagesAfter should filter the active path in SQL: %s %v", last.SQL, last.Vars)
+	}
+
+	if _, err := repo.GetLastPathMessage(5); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(last.SQL, "WITH RECURSIVE active_path") || !strings.HasSuffix(last.SQL, "LIMIT 1") {
+		t.Fatalf("GetLastPathMessage should pick the last path message in SQL: %s", last.SQL)
+	}
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinFulltextRunes ngram 全文索引能检索的最短词长（ngram_token_size 默认2），更短的词改用 LIKE
+	MinFulltextRunes = 2
+	// MaxTerms 一次检索最多使用的关键词数
+	MaxTerms = 5
+)
+
+// Terms 按空白拆分关键词，去掉重复（不区分大小写）和全文检索的引号
+func Terms(keyword string) []string {
+	var terms []string
+	for _, field := range strings.Fields(keyword) {
+		term := strings.ReplaceAll(field, `"`, "")
+		if term == "" || slices.ContainsFunc(terms, func(t string) bool { return strings.EqualFold(t, term) }) {
+			continue
+		}
+		terms = append(terms, term)
+		if len(terms) == MaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// BooleanQuery 转换为 MATCH ... AGAINST 的布尔模式表达式，每个词按短语匹配且都必须出现。
+// 有词短于 ngram 长度时返回空，调用方改用 LIKE
+func BooleanQuery(terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < MinFulltextRunes {
+			return ""
+		}
+		parts = append(parts, `+"`+term+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LikePattern 包含关键词的 LIKE 模式，转义通配符
+func LikePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// Highlight 截取命中关键词的片段，命中处用 <em></em> 标记，其余内容按 HTML 转义。
+// 每个片段包含命中前后各 radius 个字符，相邻片段合并，最多返回 maxSnippets 个；
+// 没有命中时返回内容开头的一段
+func Highlight(content string, terms []string, radius, maxSnippets int) []string {
+	runes := []rune(content)
+	matches := findMatches(runes, terms)
+	if len(matches) == 0 {
+		end := min(len(runes), 2*radius)
+		return []string{snippet(runes, 0, end, nil)}
+	}
+
+	var snippets []string
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := max(0, matches[i][0]-radius)
+		end := min(len(runes), matches[i][1]+radius)
+		j := i + 1
+		for j < len(matches) && matches[j][0]-radius <= end {
+			end = min(len(runes), matches[j][1]+radius)
+			j++
+		}
+		snippets = append(snippets, snippet(runes, start, end, matches[i:j]))
+		i = j
+	}
+	return snippets
+}
+
+// findMatches 查找所有命中区间（按字符计），按起点排序并合并重叠部分
+func findMatches(runes []rune, terms []string) [][2]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var matches [][2]int
+	for _, term := range terms {
+		target := []rune(strings.ToLower(term))
+		if len(target) == 0 {
+			continue
+		}
+		for i := 0; i+len(target) <= len(lower); i++ {
+			if slices.Equal(lower[i:i+len(target)], target) {
+				matches = append(matches, [2]int{i, i + len(target)})
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b [2]int) int { return a[0] - b[0] })
+
+	var merged [][2]int
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], m[1])
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+// snippet 输出 [start, end) 范围的内容，标记其中的命中区间，换行替换为空格
+func snippet(runes []rune, start, end int, matches [][2]int) string {
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		b.WriteString(escape(runes[pos:m[0]]))
+		b.WriteString("<em>")
+		b.WriteString(escape(runes[m[0]:m[1]]))
+		b.WriteString("</em>")
+		pos = m[1]
+	}
+	b.WriteString(escape(runes[pos:end]))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func escape(runes []rune) string {
+	text := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, string(runes))
+	return html.EscapeString(text)
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	got := Terms(`  魔法 "学院"  Magic magic 魔法 `)
+	want := []string{"魔法", "学院", "Magic"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms = %v, want %v", got, want)
+	}
+	if got := Terms(`""  `); len(got) != 0 {
+		t.Errorf("quotes only should give no terms, got %v", got)
+	}
+}
+
+func TestBooleanQuery(t *testing.T) {
+	if got := BooleanQuery([]string{"魔法", "学院"}); got != `+"魔法" +"学院"` {
+		t.Errorf("BooleanQuery = %s", got)
+	}
+	if got := BooleanQuery([]string{"魔法", "龙"}); got != "" {
+		t.Errorf("short term should fall back to LIKE, got %s", got)
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	if got := LikePattern(`50%_a\b`); got != `%50\%\_a\\b%` {
+		t.Errorf("LikePattern = %s", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	content := "今天我们去魔法学院上课，老师讲了<火焰>魔法。\n下课后大家一起去图书馆看书，一直到晚上才回宿舍休息。"
+
+	got := Highlight(content, []string{"魔法"}, 4, 3)
+	want := []string{"…天我们去<em>魔法</em>学院上课…", "…&lt;火焰&gt;<em>魔法</em>。 下课…"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+
+	// 相邻命中合并为一个片段，重叠的关键词合并标记
+	got = Highlight("Magic school magic", []string{"magic", "agic s"}, 3, 3)
+	want = []string{"<em>Magic s</em>chool <em>magic</em>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Highlight merge = %q, want %q", got, want)
+	}
+
+	if got := Highlight(content, []string{"魔法"}, 4, 1); len(got) != 1 {
+		t.Errorf("maxSnippets not applied: %q", got)
+	}
+
+	if got := Highlight(content, []string{"飞船"}, 3, 3); !reflect.DeepEqual(got, []string{"今天我们去魔…"}) {
+		t.Errorf("no match = %q", got)
+	}
+}
@@ -1,10 +1,22 @@
 package svc
 
 import (
+	"context"
+
+	"ai-roleplay/common/knowledge"
 	common "ai-roleplay/common/utils"
 	"ai-roleplay/services/chat/api/internal/config"
+	"ai-roleplay/services/chat/api/internal/export"
+	"ai-roleplay/services/chat/api/internal/generation"
+	llm_model "ai-roleplay/services/chat/api/internal/model"
+	"ai-roleplay/services/chat/api/internal/moderation"
+	"ai-roleplay/services/chat/api/internal/quota"
+	"ai-roleplay/services/chat/api/internal/ratelimit"
+	"ai-roleplay/services/chat/api/internal/tools"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
 	"gorm.io/gorm"
 )
 
@@ -12,12 +24,70 @@ type ServiceContext struct {
 	Config config.Config
 	Db     *gorm.DB
 	Redis  *redis.Client
+	LLM    *llm_model.Registry
+
+	// 角色知识库检索
+	Knowledge *knowledge.Retriever
+
+	// 进行中的流式生成
+	Generations *generation.Manager
+
+	// 角色可使用的服务端工具
+	Tools *tools.Registry
+
+	// 内容审核
+	Moderation *moderation.Moderator
+
+	// 发送消息的限流
+	Limiter *ratelimit.Limiter
+
+	// 可信的反向代理，按IP限流时据此取请求方IP
+	TrustedProxies ratelimit.TrustedProxies
+
+	// 套餐额度
+	Quota *quota.Manager
+
+	// 对话导出格式
+	Exporters *export.Registry
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	registry, err := llm_model.NewRegistry(context.Background(), c.LLM)
+	logx.Must(err)
+	embedder, err := knowledge.NewEmbedder(c.Knowledge.Embedder)
+	logx.Must(err)
+
+	proxies, err := ratelimit.ParseTrustedProxies(c.RateLimit.TrustedProxies)
+	logx.Must(err)
+
+	db := common.GetDB(c.Mysql)
+	rdb := common.GetRedis(c.Redis)
+	generations := generation.NewManager(rdb, c.Generation.MaxDuration, c.Generation.EventTTL)
+	threading.GoSafe(func() {
+		generations.Start(context.Background())
+	})
+
+	retriever := knowledge.NewRetriever(db, embedder, knowledge.NewRedisVersions(rdb), c.Knowledge.CacheTTL)
+
+	var classifier *moderation.Classifier
+	if c.Moderation.Classifier.Enable {
+		provider, err := registry.Get(c.Moderation.Classifier.Provider)
+		logx.Must(err)
+		classifier = moderation.NewClassifier(c.Moderation.Classifier, provider.ChatModel)
+	}
+
 	return &ServiceContext{
-		Config: c,
-		Db:     common.GetDB(c.Mysql),
-		Redis:  common.GetRedis(c.Redis),
+		Config:         c,
+		Db:             db,
+		Redis:          rdb,
+		LLM:            registry,
+		Knowledge:      retriever,
+		Generations:    generations,
+		Tools:          tools.NewBuiltinRegistry(retriever, c.Knowledge),
+		Moderation:     moderation.NewModerator(db, c.Moderation, classifier),
+		Limiter:        ratelimit.NewLimiter(rdb),
+		TrustedProxies: proxies,
+		Quota:          quota.NewManager(db, rdb, c.Quota),
+		Exporters:      export.NewBuiltinRegistry(),
 	}
 }
@@ -0,0 +1,62 @@
+package title
+
+import (
+	"context"
+
+	"ai-roleplay/services/chat/api/internal/prompt"
+	"ai-roleplay/services/chat/api/internal/repo"
+	"ai-roleplay/services/chat/api/internal/svc"
+	"ai-roleplay/services/chat/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// defaultTitle 新建对话的默认标题
+const defaultTitle = "新对话"
+
+// Generator 在对话的第一轮回复后自动生成标题
+type Generator struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGenerator(ctx context.Context, svcCtx *svc.ServiceContext) *Generator {
+	return &Generator{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NeedsTitle 对话仍是默认标题且用户没有设置过标题时需要生成
+func NeedsTitle(conversation *model.Conversation) bool {
+	return conversation != nil && conversation.TitleCustomized == 0 && conversation.Title == defaultTitle
+}
+
+// Generate 根据第一轮对话生成标题并保存，返回新标题；用户在此期间设置了标题时返回空
+func (g *Generator) Generate(conversationID int64, userContent, reply string) (string, error) {
+	provider, err := g.svcCtx.LLM.Get(g.svcCtx.Config.Title.Provider)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := prompt.Generate(g.ctx, provider.ChatModel, prompt.BuildTitleMessages(userContent, reply))
+	if err != nil {
+		return "", err
+	}
+	title := prompt.CleanTitle(result.Content)
+	if title == "" {
+		g.Infof("Empty title generated - ConversationId: %d", conversationID)
+		return "", nil
+	}
+
+	chatRepo := repo.NewChatServiceRepo(g.ctx, g.svcCtx)
+	updated, err := chatRepo.UpdateAutoTitle(conversationID, title)
+	if err != nil || !updated {
+		return "", err
+	}
+
+	g.Infof("Conversation titled - ConversationId: %d, Title: %s", conversationID, title)
+	return title, nil
+}
@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"ai-roleplay/common/knowledge"
+	common "ai-roleplay/common/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// NewBuiltinRegistry 注册内置工具，retriever 为 nil 时不提供知识库检索
+func NewBuiltinRegistry(retriever *knowledge.Retriever, knowledgeConf knowledge.Config) *Registry {
+	r := NewRegistry()
+	r.Register(common.AI_Tool_Roll_Dice, func(Scope) (tool.InvokableTool, error) {
+		return newDiceTool()
+	})
+	r.Register(common.AI_Tool_Current_Time, func(Scope) (tool.InvokableTool, error) {
+		return newClockTool()
+	})
+	r.Register(common.AI_Tool_Convert_Unit, func(Scope) (tool.InvokableTool, error) {
+		return newUnitTool()
+	})
+	if retriever != nil && knowledgeConf.Enable {
+		r.Register(common.AI_Tool_Search_Knowledge, func(scope Scope) (tool.InvokableTool, error) {
+			return newKnowledgeTool(retriever, knowledgeConf, scope.CharacterID)
+		})
+	}
+	return r
+}
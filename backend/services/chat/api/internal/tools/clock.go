@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata" // 容器内可能没有时区数据
+
+	common "ai-roleplay/common/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const defaultTimezone = "Asia/Shanghai"
+
+var weekdayNames = [...]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+type clockInput struct {
+	Timezone string `json:"timezone,omitempty" jsonschema:"description=IANA 时区名称，如 Asia/Shanghai、America/New_York，默认 Asia/Shanghai"`
+}
+
+type clockOutput struct {
+	Timezone string `json:"timezone"`
+	Datetime string `json:"datetime"`
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	Weekday  string `json:"weekday"`
+}
+
+func newClockTool() (tool.InvokableTool, error) {
+	return utils.InferTool(common.AI_Tool_Current_Time, "获取当前的日期、时间和星期",
+		func(ctx context.Context, in *clockInput) (*clockOutput, error) {
+			return currentTime(in.Timezone, time.Now())
+		})
+}
+
+func currentTime(timezone string, now time.Time) (*clockOutput, error) {
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("未知时区: %s", timezone)
+	}
+
+	now = now.In(loc)
+	return &clockOutput{
+		Timezone: timezone,
+		Datetime: now.Format("2006-01-02 15:04:05"),
+		Date:     now.Format("2006-01-02"),
+		Time:     now.Format("15:04:05"),
+		Weekday:  weekdayNames[now.Weekday()],
+	}, nil
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const (
+	maxDiceCount = 100
+	maxDiceSides = 1000
+)
+
+// diceExpression 形如 d20、2d6、3d8+2、1d100-5
+var diceExpression = regexp.MustCompile(`^(\d*)d(\d+)([+-]\d+)?$`)
+
+type diceInput struct {
+	Expression string `json:"expression" jsonschema:"description=骰子表达式，如 1d20、2d6+3、d100"`
+}
+
+type diceOutput struct {
+	Expression string `json:"expression"`
+	Rolls      []int  `json:"rolls"`
+	Modifier   int    `json:"modifier"`
+	Total      int    `json:"total"`
+}
+
+func newDiceTool() (tool.InvokableTool, error) {
+	return utils.InferTool(common.AI_Tool_Roll_Dice, "掷骰子，返回每颗骰子的点数和总点数，用于跑团、游戏和随机决定",
+		func(ctx context.Context, in *diceInput) (*diceOutput, error) {
+			return rollDice(in.Expression, rand.Intn)
+		})
+}
+
+// rollDice 按表达式掷骰子，intn 返回 [0, n) 的随机数
+func rollDice(expression string, intn func(n int) int) (*diceOutput, error) {
+	expr := strings.ToLower(strings.ReplaceAll(expression, " ", ""))
+	match := diceExpression.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf("无法识别的骰子表达式: %s", expression)
+	}
+
+	count := 1
+	if match[1] != "" {
+		count, _ = strconv.Atoi(match[1])
+	}
+	sides, _ := strconv.Atoi(match[2])
+	modifier := 0
+	if match[3] != "" {
+		modifier, _ = strconv.Atoi(match[3])
+	}
+	if count < 1 || count > maxDiceCount {
+		return nil, fmt.Errorf("骰子数量需在 1-%d 之间", maxDiceCount)
+	}
+	if sides < 2 || sides > maxDiceSides {
+		return nil, fmt.Errorf("骰子面数需在 2-%d 之间", maxDiceSides)
+	}
+
+	out := &diceOutput{Expression: expr, Rolls: make([]int, count), Modifier: modifier, Total: modifier}
+	for i := range out.Rolls {
+		out.Rolls[i] = intn(sides) + 1
+		out.Total += out.Rolls[i]
+	}
+	return out, nil
+}
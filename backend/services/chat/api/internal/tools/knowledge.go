@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"ai-roleplay/common/knowledge"
+	common "ai-roleplay/common/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const maxKnowledgeTopK = 8
+
+type knowledgeInput struct {
+	Query string `json:"query" jsonschema:"description=要在角色资料中查找的问题或关键词"`
+	TopK  int    `json:"top_k,omitempty" jsonschema:"description=返回的资料片段数，默认使用系统配置"`
+}
+
+type knowledgeOutput struct {
+	Results []knowledgeResult `json:"results"`
+}
+
+type knowledgeResult struct {
+	Title   string  `json:"title"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score"`
+}
+
+// newKnowledgeTool 在当前角色的知识库中检索，只能查到该角色上传的资料
+func newKnowledgeTool(retriever *knowledge.Retriever, conf knowledge.Config, characterID int64) (tool.InvokableTool, error) {
+	return utils.InferTool(common.AI_Tool_Search_Knowledge, "在角色的资料库中检索与问题相关的内容，回答设定、背景或专业问题前使用",
+		func(ctx context.Context, in *knowledgeInput) (*knowledgeOutput, error) {
+			if characterID <= 0 {
+				return nil, fmt.Errorf("当前对话没有绑定角色")
+			}
+			topK := conf.TopK
+			if in.TopK > 0 {
+				topK = min(in.TopK, maxKnowledgeTopK)
+			}
+
+			hits, err := retriever.Search(ctx, characterID, in.Query, topK, conf.MinScore)
+			if err != nil {
+				return nil, err
+			}
+			out := &knowledgeOutput{Results: make([]knowledgeResult, 0, len(hits))}
+			for _, hit := range hits {
+				out.Results = append(out.Results, knowledgeResult{
+					Title:   hit.DocumentTitle,
+					Content: hit.Content,
+					Score:   hit.Score,
+				})
+			}
+			return out, nil
+		})
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// Scope 一次对话中调用工具的上下文，部分工具需要知道当前角色
+type Scope struct {
+	CharacterID int64
+}
+
+// Factory 为一次对话创建工具实例
+type Factory func(scope Scope) (tool.InvokableTool, error)
+
+// Registry 服务端工具注册表，角色只能使用注册过的工具
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register 按名称注册工具，名称需与工具自身描述中的名称一致
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[name] = factory
+}
+
+// Names 返回已注册的工具名称
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve 按角色配置的名称创建工具，未注册的名称跳过并一并返回
+func (r *Registry) Resolve(names []string, scope Scope) ([]tool.InvokableTool, []string, error) {
+	var resolved []tool.InvokableTool
+	var unknown []string
+	for _, name := range names {
+		factory, ok := r.factories[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		t, err := factory(scope)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create tool %s: %w", name, err)
+		}
+		resolved = append(resolved, t)
+	}
+	return resolved, unknown, nil
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-roleplay/common/knowledge"
+)
+
+func TestRollDice(t *testing.T) {
+	// 固定返回最大点数，便于断言总数
+	maxRoll := func(n int) int { return n - 1 }
+
+	out, err := rollDice("2d6+3", maxRoll)
+	if err != nil {
+		t.Fatalf("rollDice failed: %v", err)
+	}
+	if len(out.Rolls) != 2 || out.Total != 15 || out.Modifier != 3 {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	out, err = rollDice(" D20 ", maxRoll)
+	if err != nil || len(out.Rolls) != 1 || out.Total != 20 {
+		t.Errorf("rollDice(d20) = %+v, %v", out, err)
+	}
+
+	for _, expr := range []string{"", "abc", "0d6", "101d6", "1d1", "2d6*2"} {
+		if _, err := rollDice(expr, maxRoll); err == nil {
+			t.Errorf("rollDice(%q) expected error", expr)
+		}
+	}
+}
+
+func TestConvertUnit(t *testing.T) {
+	cases := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{1, "km", "m", 1000},
+		{2, "斤", "kg", 1},
+		{100, "℃", "℉", 212},
+		{32, "F", "c", 0},
+		{1, "小时", "min", 60},
+		{12, "英寸", "ft", 1},
+	}
+	for _, c := range cases {
+		got, err := convertUnit(c.value, c.from, c.to)
+		if err != nil {
+			t.Errorf("convertUnit(%v, %s, %s) failed: %v", c.value, c.from, c.to, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("convertUnit(%v, %s, %s) = %v, want %v", c.value, c.from, c.to, got, c.want)
+		}
+	}
+
+	if _, err := convertUnit(1, "kg", "m"); err == nil {
+		t.Error("expected error for mismatched units")
+	}
+	if _, err := convertUnit(1, "parsec", "m"); err == nil {
+		t.Error("expected error for unknown unit")
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	now := time.Date(2026, 10, 17, 2, 30, 0, 0, time.UTC)
+	out, err := currentTime("", now)
+	if err != nil {
+		t.Fatalf("currentTime failed: %v", err)
+	}
+	if out.Datetime != "2026-10-17 10:30:00" || out.Weekday != "星期六" {
+		t.Errorf("unexpected result: %+v", out)
+	}
+	if _, err := currentTime("Mars/Olympus", now); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+func TestRegistryResolve(t *testing.T) {
+	r := NewBuiltinRegistry(nil, knowledge.Config{})
+	resolved, unknown, err := r.Resolve([]string{"roll_dice", "search_knowledge", "fly"}, Scope{CharacterID: 1})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(resolved) != 1 || strings.Join(unknown, ",") != "search_knowledge,fly" {
+		t.Fatalf("unexpected resolve result: %d tools, unknown %v", len(resolved), unknown)
+	}
+
+	info, err := resolved[0].Info(context.Background())
+	if err != nil || info.Name != "roll_dice" {
+		t.Fatalf("unexpected tool info: %+v, %v", info, err)
+	}
+	result, err := resolved[0].InvokableRun(context.Background(), `{"expression":"3d6"}`)
+	if err != nil || !strings.Contains(result, `"rolls"`) {
+		t.Errorf("InvokableRun = %s, %v", result, err)
+	}
+}
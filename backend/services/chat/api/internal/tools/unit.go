@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	common "ai-roleplay/common/utils"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// unit 线性单位，factor 为换算到该类基准单位的倍数
+type unit struct {
+	kind   string
+	factor float64
+}
+
+// linearUnits 长度以米、质量以克、体积以毫升、时间以秒为基准
+var linearUnits = map[string]unit{
+	"mm": {"length", 0.001}, "毫米": {"length", 0.001},
+	"cm": {"length", 0.01}, "厘米": {"length", 0.01},
+	"m": {"length", 1}, "米": {"length", 1},
+	"km": {"length", 1000}, "千米": {"length", 1000}, "公里": {"length", 1000},
+	"in": {"length", 0.0254}, "英寸": {"length", 0.0254},
+	"ft": {"length", 0.3048}, "英尺": {"length", 0.3048},
+	"yd": {"length", 0.9144}, "码": {"length", 0.9144},
+	"mi": {"length", 1609.344}, "英里": {"length", 1609.344},
+	"里": {"length", 500},
+
+	"mg": {"mass", 0.001}, "毫克": {"mass", 0.001},
+	"g": {"mass", 1}, "克": {"mass", 1},
+	"kg": {"mass", 1000}, "千克": {"mass", 1000}, "公斤": {"mass", 1000},
+	"t": {"mass", 1e6}, "吨": {"mass", 1e6},
+	"oz": {"mass", 28.349523125}, "盎司": {"mass", 28.349523125},
+	"lb": {"mass", 453.59237}, "磅": {"mass", 453.59237},
+	"斤": {"mass", 500}, "两": {"mass", 50},
+
+	"ml": {"volume", 1}, "毫升": {"volume", 1},
+	"l": {"volume", 1000}, "升": {"volume", 1000},
+	"gal": {"volume", 3785.411784}, "加仑": {"volume", 3785.411784},
+	"cup": {"volume", 236.5882365}, "杯": {"volume", 236.5882365},
+
+	"s": {"time", 1}, "秒": {"time", 1},
+	"min": {"time", 60}, "分钟": {"time", 60},
+	"h": {"time", 3600}, "小时": {"time", 3600},
+	"day": {"time", 86400}, "天": {"time", 86400},
+	"week": {"time", 604800}, "周": {"time", 604800},
+}
+
+// temperatureUnits 温度单位需要偏移换算，单独处理
+var temperatureUnits = map[string]string{
+	"c": "c", "°c": "c", "℃": "c", "摄氏度": "c",
+	"f": "f", "°f": "f", "℉": "f", "华氏度": "f",
+	"k": "k", "开尔文": "k",
+}
+
+type unitInput struct {
+	Value float64 `json:"value" jsonschema:"description=要换算的数值"`
+	From  string  `json:"from" jsonschema:"description=原单位，如 km、斤、℃、英尺"`
+	To    string  `json:"to" jsonschema:"description=目标单位，如 mi、kg、℉、米"`
+}
+
+type unitOutput struct {
+	Value  float64 `json:"value"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Result float64 `json:"result"`
+}
+
+func newUnitTool() (tool.InvokableTool, error) {
+	return utils.InferTool(common.AI_Tool_Convert_Unit, "换算长度、质量、体积、时间和温度单位，支持常用的中英文单位名称",
+		func(ctx context.Context, in *unitInput) (*unitOutput, error) {
+			result, err := convertUnit(in.Value, in.From, in.To)
+			if err != nil {
+				return nil, err
+			}
+			return &unitOutput{Value: in.Value, From: in.From, To: in.To, Result: result}, nil
+		})
+}
+
+func convertUnit(value float64, from, to string) (float64, error) {
+	fromKey, toKey := normalizeUnit(from), normalizeUnit(to)
+
+	fromTemp, fromIsTemp := temperatureUnits[fromKey]
+	toTemp, toIsTemp := temperatureUnits[toKey]
+	if fromIsTemp && toIsTemp {
+		return round(fromKelvin(toKelvin(value, fromTemp), toTemp)), nil
+	}
+
+	fromUnit, ok := linearUnits[fromKey]
+	if !ok {
+		return 0, fmt.Errorf("不支持的单位: %s", from)
+	}
+	toUnit, ok := linearUnits[toKey]
+	if !ok {
+		return 0, fmt.Errorf("不支持的单位: %s", to)
+	}
+	if fromUnit.kind != toUnit.kind {
+		return 0, fmt.Errorf("%s 和 %s 不是同一类单位", from, to)
+	}
+	return round(value * fromUnit.factor / toUnit.factor), nil
+}
+
+func normalizeUnit(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func toKelvin(value float64, unit string) float64 {
+	switch unit {
+	case "c":
+		return value + 273.15
+	case "f":
+		return (value-32)*5/9 + 273.15
+	default:
+		return value
+	}
+}
+
+func fromKelvin(value float64, unit string) float64 {
+	switch unit {
+	case "c":
+		return value - 273.15
+	case "f":
+		return (value-273.15)*9/5 + 32
+	default:
+		return value
+	}
+}
+
+// round 保留6位有效小数，避免浮点误差出现在回复中
+func round(value float64) float64 {
+	return math.Round(value*1e6) / 1e6
+}
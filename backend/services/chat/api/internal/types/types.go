@@ -12,6 +12,11 @@ type BatchDeleteRequest struct {
 	ConversationIDs []int64 `json:"conversation_ids"`
 }
 
+type CharacterRatingRequest struct {
+	ID    int64 `path:"id"`    // 角色ID
+	Score int   `json:"score"` // 评分：1-5星
+}
+
 type ChatBeforeRequest struct {
 	UserId int64 `form:"user_id"`
 }
@@ -23,16 +28,27 @@ type ChatBeforeResponse struct {
 }
 
 type ChatSSEEvent struct {
-	Type           string `json:"type"`                      // 事件类型：message/error/done/thinking
-	Content        string `json:"content,omitempty"`         // 完整内容（累积）
-	Delta          string `json:"delta,omitempty"`           // 增量内容（本次新增）
-	Done           bool   `json:"done,omitempty"`            // 是否完成
-	Error          string `json:"error,omitempty"`           // 错误信息
-	MessageId      int64  `json:"message_id,omitempty"`      // 保存后的消息ID
-	TokensIn       int64  `json:"tokens_in,omitempty"`       // 输入token数
-	TokensOut      int64  `json:"tokens_out,omitempty"`      // 输出token数
-	LatencyMs      int64  `json:"latency_ms,omitempty"`      // 响应延迟
-	ConversationID int64  `json:"conversation_id,omitempty"` // 对话ID
+	Type           string                 `json:"type"`                      // 事件类型：conversation/message/error/done/thinking/title/tool_call/tool_result
+	Content        string                 `json:"content,omitempty"`         // 完整内容（累积）
+	Delta          string                 `json:"delta,omitempty"`           // 增量内容（本次新增）
+	Done           bool                   `json:"done,omitempty"`            // 是否完成
+	Error          string                 `json:"error,omitempty"`           // 错误信息
+	MessageId      int64                  `json:"message_id,omitempty"`      // 保存后的消息ID
+	TokensIn       int64                  `json:"tokens_in,omitempty"`       // 输入token数
+	TokensOut      int64                  `json:"tokens_out,omitempty"`      // 输出token数
+	LatencyMs      int64                  `json:"latency_ms,omitempty"`      // 响应延迟
+	ConversationID int64                  `json:"conversation_id,omitempty"` // 对话ID
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`        // 附加信息，如历史裁剪情况
+	ParentID       int64                  `json:"parent_id,omitempty"`       // 回复所对应的用户消息ID
+	Branch         int                    `json:"branch,omitempty"`          // 回复在兄弟消息中的分支序号
+	GenerationID   string                 `json:"generation_id,omitempty"`   // 生成ID，用于停止生成和断线续传
+	Seq            int64                  `json:"seq,omitempty"`             // 事件在本次生成中的序号
+	RequestID      string                 `json:"request_id,omitempty"`      // WebSocket 请求ID，原样带回便于客户端对应请求
+	Title          string                 `json:"title,omitempty"`           // 自动生成的对话标题（title 事件）
+	Tool           *ToolCallInfo          `json:"tool,omitempty"`            // 工具调用信息（tool_call/tool_result 事件）
+	CharacterID    int64                  `json:"character_id,omitempty"`    // 本轮发言的角色ID
+	CharacterName  string                 `json:"character_name,omitempty"`  // 本轮发言的角色名称，群聊中据此区分发言者
+	RetryAfter     int64                  `json:"retry_after,omitempty"`     // 被限流时建议等待的秒数（error 事件）
 }
 
 type ChatSendRequest struct {
@@ -40,6 +56,18 @@ type ChatSendRequest struct {
 	ConversationId int64  `form:"conversation_id"`
 	MessageType    int64  `form:"message_type"`
 	Content        string `form:"content"`
+	Model          string `form:"model,optional"` // 模型提供方名称，为空时使用默认
+}
+
+type ChatWsMessage struct {
+	Type           string `json:"type"`                      // 消息类型：send/cancel/regenerate/ping
+	RequestID      string `json:"request_id,omitempty"`      // 客户端请求ID，随该请求产生的事件返回
+	ConversationID int64  `json:"conversation_id,omitempty"` // send：对话ID，为0时创建新对话
+	CharacterID    int64  `json:"character_id,omitempty"`    // send：角色ID
+	Content        string `json:"content,omitempty"`         // send：消息内容
+	Model          string `json:"model,omitempty"`           // send/regenerate：模型提供方名称，为空时使用默认
+	MessageID      int64  `json:"message_id,omitempty"`      // regenerate：要重新生成的AI消息ID
+	GenerationID   string `json:"generation_id,omitempty"`   // cancel：要停止的生成ID
 }
 
 type Conversation struct {
@@ -50,8 +78,9 @@ type Conversation struct {
 	StartTime       string    `json:"start_time"`
 	LastMessageTime string    `json:"last_message_time"`
 	MessageCount    int       `json:"message_count"`
-	Status          int       `json:"status"`             // 1:正常 2:已删除
-	Settings        string    `json:"settings,omitempty"` // JSON字符串，存储对话设置
+	Status          int       `json:"status"`                // 1:正常 2:已删除
+	Settings        string    `json:"settings,omitempty"`    // JSON字符串，存储对话设置
+	TurnPolicy      string    `json:"turn_policy,omitempty"` // 群聊发言策略，为空表示单角色对话
 	Messages        []Message `json:"messages,omitempty"`
 }
 
@@ -102,12 +131,66 @@ type CreateConversationResponse struct {
 	Conversation Conversation `json:"conversation"`
 }
 
-type ExportResponse struct {
-	Code     int    `json:"code"`
-	Msg      string `json:"msg"`
-	Data     string `json:"data"`     // 导出的数据内容
-	Format   string `json:"format"`   // 导出格式 json/txt/csv
-	Filename string `json:"filename"` // 建议的文件名
+type CreateGroupRequest struct {
+	Title        string  `json:"title,optional"`       // 群聊标题，为空时自动生成
+	CharacterIDs []int64 `json:"character_ids"`        // 邀请的角色ID，按轮流发言的顺序排列
+	TurnPolicy   string  `json:"turn_policy,optional"` // 发言策略：round_robin/mention/auto，默认 mention
+}
+
+type EditMessageRequest struct {
+	ID      int64  `path:"id"`             // 要编辑的用户消息ID
+	Content string `form:"content"`        // 编辑后的内容
+	Model   string `form:"model,optional"` // 模型提供方名称，为空时使用默认
+}
+
+type ExportRequest struct {
+	ID     int64  `path:"id"`
+	Format string `form:"format,optional,default=txt"` // 导出格式：txt/markdown/json/html/csv
+}
+
+type FeedbackExportItem struct {
+	MessageID      int64    `json:"message_id"`
+	ConversationID int64    `json:"conversation_id"`
+	CharacterID    int64    `json:"character_id"`
+	CharacterName  string   `json:"character_name"`
+	Vote           string   `json:"vote"`               // 评价：up赞 down踩
+	Reasons        []string `json:"reasons"`            // 原因标签
+	Comment        string   `json:"comment"`            // 补充说明
+	Question       string   `json:"question"`           // 对应的用户消息
+	Reply          string   `json:"reply"`              // 被评价的AI回复
+	Provider       string   `json:"provider,omitempty"` // 生成回复的模型提供方
+	Model          string   `json:"model,omitempty"`    // 生成回复的模型
+	UpdatedAt      string   `json:"updated_at"`         // 评价时间
+}
+
+type FeedbackExportRequest struct {
+	Page        int    `form:"page,optional,default=1"`
+	PageSize    int    `form:"page_size,optional,default=100"` // 每页条数，最多1000
+	Vote        string `form:"vote,optional"`                  // 评价：up/down，为空时不筛选
+	CharacterID int64  `form:"character_id,optional"`          // 只导出该角色的反馈，为0时不筛选
+	StartDate   string `form:"start_date,optional"`            // 开始日期 YYYY-MM-DD，默认最近30天
+	EndDate     string `form:"end_date,optional"`              // 结束日期 YYYY-MM-DD（含当天），默认今天
+}
+
+type FeedbackExportResponse struct {
+	List     []FeedbackExportItem `json:"list"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+type FeedbackRequest struct {
+	ID      int64    `path:"id"`               // AI消息ID
+	Vote    string   `json:"vote"`             // 评价：up赞 down踩
+	Reasons []string `json:"reasons,optional"` // 原因标签，赞：helpful/in_character/creative/accurate，踩：out_of_character/inaccurate/unhelpful/repetitive/too_long/too_short/unsafe
+	Comment string   `json:"comment,optional"` // 补充说明，最多500字
+}
+
+type FeedbackResponse struct {
+	MessageID int64    `json:"message_id"`
+	Vote      string   `json:"vote"`    // 评价：up赞 down踩
+	Reasons   []string `json:"reasons"` // 原因标签
+	Comment   string   `json:"comment"` // 补充说明
 }
 
 type HistoryItem struct {
@@ -117,15 +200,51 @@ type HistoryItem struct {
 	CreatedAt      string `json:"created_at"`
 }
 
+type GenerationRequest struct {
+	ID string `path:"id"` // 生成ID
+}
+
+type GroupConversationResponse struct {
+	ConversationID int64             `json:"conversation_id"`
+	Title          string            `json:"title"`
+	TurnPolicy     string            `json:"turn_policy"`
+	Participants   []ParticipantItem `json:"participants"`
+}
+
+type MemoryItem struct {
+	ID          int64  `json:"id"`
+	CharacterID int64  `json:"character_id"`
+	Content     string `json:"content"`
+	Importance  int    `json:"importance"` // 1-5
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type MemoryListRequest struct {
+	CharacterID int64 `form:"character_id,optional"` // 为空时返回所有角色的记忆
+}
+
+type MemoryListResponse struct {
+	List  []MemoryItem `json:"list"`
+	Total int          `json:"total"`
+}
+
+type MemoryRequest struct {
+	ID int64 `path:"id"`
+}
+
 type Message struct {
-	ID             int64  `json:"id"`
-	ConversationID int64  `json:"conversation_id"`
-	Type           string `json:"type"` // user/ai
-	Content        string `json:"content"`
-	AudioURL       string `json:"audio_url,omitempty"`
-	AudioDuration  int    `json:"audio_duration,omitempty"`
-	Timestamp      string `json:"timestamp"`
-	Metadata       string `json:"metadata,omitempty"` // JSON字符串，存储额外信息
+	ID             int64   `json:"id"`
+	ConversationID int64   `json:"conversation_id"`
+	Type           string  `json:"type"` // user/ai
+	Content        string  `json:"content"`
+	AudioURL       string  `json:"audio_url,omitempty"`
+	AudioDuration  int     `json:"audio_duration,omitempty"`
+	Timestamp      string  `json:"timestamp"`
+	Metadata       string  `json:"metadata,omitempty"`     // JSON字符串，存储额外信息
+	ParentID       int64   `json:"parent_id,omitempty"`    // 上一条消息ID
+	Branch         int     `json:"branch"`                 // 在兄弟消息中的分支序号
+	Siblings       []int64 `json:"siblings,omitempty"`     // 同一父消息下所有分支的消息ID，按分支序号排列
+	CharacterID    int64   `json:"character_id,omitempty"` // 发言角色ID（AI消息）
 }
 
 type MessageListRequest struct {
@@ -143,6 +262,113 @@ type MessageListResponse struct {
 	HasMore  bool      `json:"has_more"`
 }
 
+type MessageRequest struct {
+	ID int64 `path:"id"`
+}
+
+type MessageSearchItem struct {
+	MessageID         int64    `json:"message_id"`
+	ConversationID    int64    `json:"conversation_id"`
+	ConversationTitle string   `json:"conversation_title"`
+	CharacterID       int64    `json:"character_id"`
+	CharacterName     string   `json:"character_name"`
+	Type              string   `json:"type"`      // 消息类型：user用户 ai角色
+	IsActive          bool     `json:"is_active"` // 是否为当前选中的分支，否则需先切换分支再跳转
+	Snippets          []string `json:"snippets"`  // 命中片段，已做HTML转义，关键词用<em></em>标记
+	CreatedAt         string   `json:"created_at"`
+}
+
+type MessageSearchRequest struct {
+	Keyword     string `form:"keyword"` // 关键词，多个词用空格分隔，需全部出现
+	Page        int    `form:"page,optional,default=1"`
+	PageSize    int    `form:"page_size,optional,default=20"` // 每页条数，最多100
+	CharacterID int64  `form:"character_id,optional"`         // 只检索该角色的对话，为0时不筛选
+	StartDate   string `form:"start_date,optional"`           // 开始日期 YYYY-MM-DD，为空时不限
+	EndDate     string `form:"end_date,optional"`             // 结束日期 YYYY-MM-DD（含当天），为空时不限
+}
+
+type MessageSearchResponse struct {
+	List     []MessageSearchItem `json:"list"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+type ModerationFinding struct {
+	Checker string `json:"checker"`         // 检查器：rule/spam/classifier
+	Action  string `json:"action"`          // 处理方式
+	Reason  string `json:"reason"`          // 命中原因
+	Match   string `json:"match,omitempty"` // 命中的原文片段
+}
+
+type ModerationItem struct {
+	MessageID      int64               `json:"message_id"`
+	ConversationID int64               `json:"conversation_id"`
+	Type           string              `json:"type"`               // user/ai
+	Content        string              `json:"content"`            // 保存的内容（可能已遮蔽或替换）
+	Original       string              `json:"original,omitempty"` // 遮蔽或拦截前的原文，仅管理员可见
+	Stage          string              `json:"stage"`
+	Action         string              `json:"action"`
+	Findings       []ModerationFinding `json:"findings"`
+	CreatedAt      string              `json:"created_at"`
+}
+
+type ModerationListRequest struct {
+	Page     int    `form:"page,optional,default=1"`
+	PageSize int    `form:"page_size,optional,default=20"`
+	Stage    string `form:"stage,optional"`  // 审核阶段：input用户输入 output模型输出，为空时不筛选
+	Action   string `form:"action,optional"` // 处理方式：flag/mask/block，为空时不筛选
+}
+
+type ModerationListResponse struct {
+	List     []ModerationItem `json:"list"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+type QuotaItem struct {
+	Used      int64  `json:"used"`      // 已用token数
+	Limit     int64  `json:"limit"`     // 额度，0 表示不限
+	Remaining int64  `json:"remaining"` // 剩余额度，不限时为 -1
+	ResetAt   string `json:"reset_at"`  // 额度重置时间
+}
+
+type QuotaResponse struct {
+	Plan    string    `json:"plan"`    // 套餐：free, pro
+	Daily   QuotaItem `json:"daily"`   // 每日额度
+	Monthly QuotaItem `json:"monthly"` // 每月额度
+}
+
+type ParticipantItem struct {
+	CharacterID int64  `json:"character_id"`
+	Name        string `json:"name"`
+	Avatar      string `json:"avatar,omitempty"`
+	Position    int    `json:"position"` // 轮流发言的顺序
+}
+
+type PromptPreviewRequest struct {
+	CharacterID int64 `form:"character_id"`
+}
+
+type PromptPreviewResponse struct {
+	CharacterID   int64  `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	Policy        string `json:"policy"`        // 平台策略
+	Persona       string `json:"persona"`       // 角色设定
+	SystemPrompt  string `json:"system_prompt"` // 完整系统提示词
+}
+
+type RegenerateRequest struct {
+	ID    int64  `path:"id"`             // 要重新生成的AI消息ID
+	Model string `form:"model,optional"` // 模型提供方名称，为空时使用默认
+}
+
+type ResumeGenerationRequest struct {
+	ID      string `path:"id"`                // 生成ID
+	LastSeq int64  `form:"last_seq,optional"` // 客户端已收到的最后一个事件序号，请求头 Last-Event-ID 优先
+}
+
 type SearchConversationRequest struct {
 	Keyword   string `form:"keyword"`
 	Page      int    `form:"page,optional,default=1"`
@@ -167,7 +393,52 @@ type SendMessageResponse struct {
 	AIMessage   Message `json:"ai_message"`
 }
 
+type SummaryResponse struct {
+	ConversationID   int64  `json:"conversation_id"`
+	Summary          string `json:"summary"`
+	CoveredMessageID int64  `json:"covered_message_id"` // 摘要覆盖到的最后一条消息ID
+	MessageCount     int    `json:"message_count"`      // 摘要覆盖的消息数量
+	UpdatedAt        string `json:"updated_at,omitempty"`
+}
+
+type UsageItem struct {
+	Period              string  `json:"period,omitempty"`      // 统计周期，如 2026-10-17、2026-W42、2026-10
+	MessageCount        int64   `json:"message_count"`         // AI回复数
+	TotalTokens         int64   `json:"total_tokens"`          // token总数
+	TotalProcessingTime int64   `json:"total_processing_time"` // 总耗时（毫秒）
+	AvgProcessingTime   float64 `json:"avg_processing_time"`   // 平均耗时（毫秒）
+}
+
+type UsageRequest struct {
+	ConversationID int64  `form:"conversation_id,optional"` // 只统计该对话，为0时统计用户的全部对话
+	StartDate      string `form:"start_date,optional"`      // 开始日期 YYYY-MM-DD，默认最近30天
+	EndDate        string `form:"end_date,optional"`        // 结束日期 YYYY-MM-DD（含当天），默认今天
+	Period         string `form:"period,optional"`          // 统计周期：day/week/month，默认 day
+}
+
+type UsageResponse struct {
+	Period string         `json:"period"`          // 统计周期
+	Total  UsageItem      `json:"total"`           // 时间范围内的合计
+	List   []UsageItem    `json:"list"`            // 按周期的明细
+	Quota  *QuotaResponse `json:"quota,omitempty"` // 当前套餐的额度，未启用额度时为空
+}
+
+type ToolCallInfo struct {
+	ID        string `json:"id"`                  // 工具调用ID，tool_call 与 tool_result 事件据此对应
+	Name      string `json:"name"`                // 工具名称
+	Arguments string `json:"arguments,omitempty"` // 调用参数（JSON）
+	Result    string `json:"result,omitempty"`    // 工具返回结果（JSON）
+	Error     string `json:"error,omitempty"`     // 工具执行失败的原因
+}
+
+type UpdateMemoryRequest struct {
+	ID         int64  `path:"id"`
+	Content    string `json:"content"`
+	Importance int    `json:"importance,optional"`
+}
+
 type UpdateTitleRequest struct {
+	ID    int64  `path:"id"`
 	Title string `json:"title"`
 }
 
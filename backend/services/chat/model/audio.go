@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// AudioFile 语音文件，由语音服务写入，消息通过 audio_id 引用
+type AudioFile struct {
+	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
+	Type        string    `gorm:"column:type" json:"type"` // 'stt', 'tts'
+	Filename    string    `gorm:"column:filename" json:"filename"`
+	FilePath    string    `gorm:"column:file_path" json:"file_path"`
+	FileSize    int64     `gorm:"column:file_size" json:"file_size"` // 字节
+	Duration    int32     `gorm:"column:duration" json:"duration"`   // 毫秒
+	Format      string    `gorm:"column:format" json:"format"`
+	TextContent *string   `gorm:"column:text_content" json:"text_content"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AudioFile) TableName() string {
+	return "audio_files"
+}
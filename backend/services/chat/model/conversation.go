@@ -6,13 +6,20 @@ import (
 
 // Conversation 对话模型
 type Conversation struct {
-	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
-	UserID      *int64    `gorm:"column:user_id" json:"user_id"`
-	CharacterID int64     `gorm:"column:character_id" json:"character_id"`
-	Title       string    `gorm:"column:title" json:"title"`
-	Status      int32     `gorm:"column:status" json:"status"`
-	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+	ID              int64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID          *int64    `gorm:"column:user_id" json:"user_id"`
+	CharacterID     int64     `gorm:"column:character_id" json:"character_id"`
+	Title           string    `gorm:"column:title" json:"title"`
+	TitleCustomized int32     `gorm:"column:title_customized;default:0" json:"title_customized"` // 标题是否由用户设置，设置后不再自动生成
+	TurnPolicy      string    `gorm:"column:turn_policy;default:''" json:"turn_policy"`          // 群聊发言策略，为空表示单角色对话
+	Status          int32     `gorm:"column:status" json:"status"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// IsGroup 是否为多角色群聊
+func (c *Conversation) IsGroup() bool {
+	return c.TurnPolicy != ""
 }
 
 // TableName 指定表名
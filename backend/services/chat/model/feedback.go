@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+)
+
+// MessageFeedback 用户对AI回复的反馈
+type MessageFeedback struct {
+	ID             int64     `gorm:"primaryKey;column:id" json:"id"`
+	MessageID      int64     `gorm:"column:message_id" json:"message_id"`
+	UserID         int64     `gorm:"column:user_id" json:"user_id"`
+	ConversationID int64     `gorm:"column:conversation_id" json:"conversation_id"`
+	CharacterID    *int64    `gorm:"column:character_id" json:"character_id"`
+	Vote           int8      `gorm:"column:vote" json:"vote"`       // 1赞 -1踩
+	Reasons        *string   `gorm:"column:reasons" json:"reasons"` // JSON数组，原因标签
+	Comment        string    `gorm:"column:comment" json:"comment"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MessageFeedback) TableName() string {
+	return "message_feedback"
+}
+
+// CharacterRating 用户对角色的星级评分
+type CharacterRating struct {
+	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
+	CharacterID int64     `gorm:"column:character_id" json:"character_id"`
+	UserID      int64     `gorm:"column:user_id" json:"user_id"`
+	Score       int32     `gorm:"column:score" json:"score"` // 1-5
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CharacterRating) TableName() string {
+	return "character_ratings"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// UserCharacterMemory 角色关于用户的长期记忆
+type UserCharacterMemory struct {
+	ID              int64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID          int64     `gorm:"column:user_id" json:"user_id"`
+	CharacterID     int64     `gorm:"column:character_id" json:"character_id"`
+	FactKey         string    `gorm:"column:fact_key;default:''" json:"fact_key"` // 事实主题，同一主题只保留最新一条
+	Content         string    `gorm:"column:content" json:"content"`
+	Importance      int32     `gorm:"column:importance;default:3" json:"importance"` // 1-5
+	SourceMessageID *int64    `gorm:"column:source_message_id" json:"source_message_id"`
+	Status          int32     `gorm:"column:status;default:1" json:"status"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserCharacterMemory) TableName() string {
+	return "user_character_memories"
+}
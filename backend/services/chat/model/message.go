@@ -6,15 +6,20 @@ import (
 
 // Message 消息模型
 type Message struct {
-	ID             int64     `gorm:"primaryKey;column:id" json:"id"`
-	ConversationID int64     `gorm:"column:conversation_id" json:"conversation_id"`
-	Type           string    `gorm:"column:type" json:"type"` // 'user', 'ai'
-	Content        string    `gorm:"column:content" json:"content"`
-	AudioID        *int64    `gorm:"column:audio_id" json:"audio_id"`
-	Metadata       *string   `gorm:"column:metadata" json:"metadata"` // JSON字符串
-	TokenUsed      int32     `gorm:"column:token_used;default:0" json:"token_used"`
-	ProcessingTime int32     `gorm:"column:processing_time;default:0" json:"processing_time"` // 毫秒
-	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	ID              int64     `gorm:"primaryKey;column:id" json:"id"`
+	ConversationID  int64     `gorm:"column:conversation_id" json:"conversation_id"`
+	ParentID        *int64    `gorm:"column:parent_id" json:"parent_id"`           // 上一条消息，为空表示对话的第一条
+	Branch          int32     `gorm:"column:branch;default:1" json:"branch"`       // 在同一父消息下的分支序号，从1开始
+	IsActive        int32     `gorm:"column:is_active;default:1" json:"is_active"` // 是否为兄弟消息中当前选中的分支
+	Type            string    `gorm:"column:type" json:"type"`                     // 'user', 'ai'
+	CharacterID     *int64    `gorm:"column:character_id" json:"character_id"`     // 发言角色，AI消息记录由哪个角色回复
+	Content         string    `gorm:"column:content" json:"content"`
+	OriginalContent *string   `gorm:"column:original_content" json:"-"` // 被遮蔽或拦截前的原文，仅管理员可见，不随消息返回
+	AudioID         *int64    `gorm:"column:audio_id" json:"audio_id"`
+	Metadata        *string   `gorm:"column:metadata" json:"metadata"` // JSON字符串
+	TokenUsed       int32     `gorm:"column:token_used;default:0" json:"token_used"`
+	ProcessingTime  int32     `gorm:"column:processing_time;default:0" json:"processing_time"` // 毫秒
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
 }
 
 // TableName 指定表名
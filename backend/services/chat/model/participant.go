@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// ConversationParticipant 群聊参与角色
+type ConversationParticipant struct {
+	ID             int64     `gorm:"primaryKey;column:id" json:"id"`
+	ConversationID int64     `gorm:"column:conversation_id" json:"conversation_id"`
+	CharacterID    int64     `gorm:"column:character_id" json:"character_id"`
+	Position       int32     `gorm:"column:position;default:0" json:"position"` // 轮流发言的顺序，从0开始
+	Status         int32     `gorm:"column:status;default:1" json:"status"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ConversationParticipant) TableName() string {
+	return "conversation_participants"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// UserPlan 用户套餐
+type UserPlan struct {
+	ID        int64      `gorm:"primaryKey;column:id" json:"id"`
+	UserID    int64      `gorm:"column:user_id" json:"user_id"`
+	Plan      string     `gorm:"column:plan;default:free" json:"plan"` // free/pro，额度在配置中定义
+	ExpiresAt *time.Time `gorm:"column:expires_at" json:"expires_at"`  // 为空表示长期有效
+	CreatedAt time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserPlan) TableName() string {
+	return "user_plans"
+}
+
+// UserTokenUsage 用户按天、按月累计的token用量
+type UserTokenUsage struct {
+	ID         int64     `gorm:"primaryKey;column:id" json:"id"`
+	UserID     int64     `gorm:"column:user_id" json:"user_id"`
+	PeriodType string    `gorm:"column:period_type" json:"period_type"` // day/month
+	Period     string    `gorm:"column:period" json:"period"`           // 2026-10-17、2026-10
+	TokensUsed int64     `gorm:"column:tokens_used" json:"tokens_used"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserTokenUsage) TableName() string {
+	return "user_token_usage"
+}
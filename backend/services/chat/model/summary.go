@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// ConversationSummary 对话滚动摘要
+type ConversationSummary struct {
+	ID               int64     `gorm:"primaryKey;column:id" json:"id"`
+	ConversationID   int64     `gorm:"column:conversation_id" json:"conversation_id"`
+	Summary          string    `gorm:"column:summary" json:"summary"`
+	CoveredMessageID int64     `gorm:"column:covered_message_id" json:"covered_message_id"` // 摘要覆盖到的最后一条消息ID
+	MessageCount     int32     `gorm:"column:message_count" json:"message_count"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ConversationSummary) TableName() string {
+	return "conversation_summaries"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// SystemConfig 系统配置
+type SystemConfig struct {
+	ID          int64     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigKey   string    `gorm:"column:config_key" json:"config_key"`
+	ConfigValue *string   `gorm:"column:config_value" json:"config_value"`
+	Description *string   `gorm:"column:description" json:"description"`
+	ConfigType  string    `gorm:"column:config_type" json:"config_type"` // string,number,boolean,json
+	IsPublic    int32     `gorm:"column:is_public" json:"is_public"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SystemConfig) TableName() string {
+	return "system_configs"
+}
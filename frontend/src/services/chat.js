@@ -193,11 +193,11 @@ export const chatApiService = {
     })
   },
 
-  // 导出对话记录
-  exportConversation(id, format = 'json') {
-    return chatApi.get(`/api/chat/conversation/${id}/export`, {
-      params: { format }
-    })
+  // 导出对话记录：后端直接返回文件，这里只生成下载地址
+  // format 可选 txt/markdown/json/html/csv
+  exportConversation(id, format = 'txt') {
+    const baseURL = chatApi.defaults?.baseURL || ''
+    return `${baseURL}/api/chat/conversation/${id}/export?format=${encodeURIComponent(format)}`
   },
 
   // 批量删除对话